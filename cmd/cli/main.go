@@ -1,13 +1,17 @@
 package main
 
 import (
-	"log"
+	"fmt"
+	"os"
 
-	"sm-cli/pkg/app"
+	"sm-cli/pkg/cli"
 )
 
 func main() {
-	if err := app.Run(); err != nil {
-		log.Fatalf("cli exited with error: %v", err)
+	if err := cli.Run(os.Args[1:]); err != nil {
+		if !cli.Silent(err) {
+			fmt.Fprintf(os.Stderr, "sm-cli: %v\n", err)
+		}
+		os.Exit(cli.ExitCode(err))
 	}
 }
@@ -2,145 +2,467 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
-var BackendURL = "http://localhost:8080"
-var token string
+// DefaultBaseURL is the backend used when no WithBaseURL option is given.
+const DefaultBaseURL = "http://localhost:8080"
 
-func SetToken(t string) {
-	token = t
+const defaultUserAgent = "sm-cli"
+
+// Client talks to a single Secrets Vault backend. It is safe for concurrent
+// use; the token can be swapped at any time with SetToken.
+type Client struct {
+	baseURL       string
+	userAgent     string
+	httpClient    *http.Client
+	healthTimeout time.Duration
+	retry         RetryPolicy
+	refresher     Refresher
+	onToken       func(token string)
+
+	mu        sync.RWMutex
+	token     string
+	refreshMu sync.Mutex
+}
+
+// Option configures a Client in NewClient.
+type Option func(*Client)
+
+// WithBaseURL sets the backend root, e.g. "https://vault.example.com".
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(u, "/")
+	}
+}
+
+// WithToken sets the bearer token (JWT or API key) sent with every request.
+func WithToken(t string) Option {
+	return func(c *Client) {
+		c.token = t
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithTimeout sets the overall timeout for a single request. Deadlines on the
+// context passed to a call apply as well; whichever is shorter wins.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Timeout = d
+		c.httpClient = &hc
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for https backends, e.g. to
+// trust a private CA.
+func WithTLSConfig(tc *tls.Config) Option {
+	return func(c *Client) {
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = tc
+		hc := *c.httpClient
+		hc.Transport = tr
+		c.httpClient = &hc
+	}
+}
+
+// WithHealthTimeout sets the timeout used by Health, which is kept short so
+// startup checks do not hang.
+func WithHealthTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.healthTimeout = d
+	}
+}
+
+// NewClient returns a Client for DefaultBaseURL, modified by opts.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:       DefaultBaseURL,
+		userAgent:     defaultUserAgent,
+		httpClient:    &http.Client{Timeout: 8 * time.Second},
+		healthTimeout: 3 * time.Second,
+		retry:         DefaultRetryPolicy,
+		refresher:     RefreshEndpoint,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the backend root this client talks to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// SetToken replaces the bearer token. An empty token logs the client out.
+func (c *Client) SetToken(t string) {
+	c.mu.Lock()
+	c.token = t
+	c.mu.Unlock()
+}
+
+// Token returns the current bearer token.
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
 }
 
-func doRequest(req *http.Request) (*http.Response, error) {
-	client := http.Client{Timeout: 8 * time.Second}
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
+func (c *Client) HasToken() bool {
+	return c.Token() != ""
+}
+
+// withToken returns a shallow copy of c that authenticates with t. The copy
+// never refreshes, so validating or refreshing a token cannot recurse.
+func (c *Client) withToken(t string) *Client {
+	return &Client{
+		baseURL:       c.baseURL,
+		userAgent:     c.userAgent,
+		httpClient:    c.httpClient,
+		healthTimeout: c.healthTimeout,
+		retry:         c.retry,
+		token:         t,
 	}
-	return client.Do(req)
 }
 
-func Health() (map[string]interface{}, error) {
-	client := http.Client{Timeout: 3 * time.Second}
-	resp, err := client.Get(BackendURL + "/health")
+// newRequest builds a request against path, JSON-encoding body when non-nil.
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, r)
 	if err != nil {
 		return nil, err
 	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if t := c.Token(); t != "" {
+		req.Header.Set("Authorization", "Bearer "+t)
+	}
+	return req, nil
+}
+
+// send performs req and decodes a successful JSON body into out (which may be
+// nil). Any non-2xx status is returned as an *Error. An expired JWT is
+// refreshed and the request replayed once.
+func (c *Client) send(req *http.Request, out interface{}) error {
+	err := c.sendWithRetry(req, out)
+	if !errors.Is(err, ErrUnauthorized) || !c.canRefresh(req) {
+		return err
+	}
+	token, rerr := c.refreshToken(req.Context(), bearer(req))
+	if rerr != nil {
+		return err
+	}
+	next, rerr := rewind(req)
+	if rerr != nil {
+		return err
+	}
+	next.Header.Set("Authorization", "Bearer "+token)
+	return c.sendWithRetry(next, out)
+}
+
+// sendWithRetry retries transient failures according to the client's
+// RetryPolicy when req is safe to repeat.
+func (c *Client) sendWithRetry(req *http.Request, out interface{}) error {
+	canRetry := retryable(req)
+	for attempt := 1; ; attempt++ {
+		temporary, err := c.sendOnce(req, out)
+		if err == nil || !canRetry || !temporary || attempt >= c.retry.MaxAttempts {
+			return err
+		}
+		delay, ok := c.retry.backoff(attempt, err)
+		if !ok || sleep(req.Context(), delay) != nil {
+			return err
+		}
+		next, rerr := rewind(req)
+		if rerr != nil {
+			return err
+		}
+		req = next
+	}
+}
+
+// sendOnce performs a single attempt of req. temporary reports whether the
+// failure is worth retrying: transport errors and 429/502/503/504 responses.
+func (c *Client) sendOnce(req *http.Request, out interface{}) (temporary bool, err error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// a cancelled or expired context is final
+		return req.Context().Err() == nil, withKey(req, err)
+	}
 	defer resp.Body.Close()
-	b, _ := ioutil.ReadAll(resp.Body)
-	var m map[string]interface{}
-	json.Unmarshal(b, &m)
-	return m, nil
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return req.Context().Err() == nil, withKey(req, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := newError(req, resp, b)
+		return apiErr.Temporary(), apiErr
+	}
+	if out == nil || len(bytes.TrimSpace(b)) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return false, withKey(req, fmt.Errorf("%s %s: decode response: %w", req.Method, req.URL.Path, err))
+	}
+	return false, nil
 }
 
-func Signup(email, password, master string) (*http.Response, error) {
+func (c *Client) Health(ctx context.Context) (*HealthStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.healthTimeout)
+	defer cancel()
+	req, err := c.newRequest(ctx, "GET", "/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return nil, newError(req, resp, b)
+	}
+	var hs HealthStatus
+	if err := json.Unmarshal(b, &hs); err != nil || hs.Status == "" {
+		hs.Status = "ok"
+	}
+	return &hs, nil
+}
+
+func (c *Client) Signup(ctx context.Context, email, password, master string) (*AuthResponse, error) {
 	body := map[string]string{"email": email, "password": password, "master_password": master}
-	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest("POST", BackendURL+"/api/v1/auth/signup", bytes.NewReader(b))
-	req.Header.Set("Content-Type", "application/json")
-	return doRequest(req)
+	req, err := c.newRequest(ctx, "POST", "/api/v1/auth/signup", body)
+	if err != nil {
+		return nil, err
+	}
+	setIdempotencyKey(req)
+	var out AuthResponse
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	if out.Token == "" {
+		return nil, fmt.Errorf("signup: no token in response")
+	}
+	return &out, nil
 }
 
-func Login(email, password, master string) (*http.Response, error) {
+func (c *Client) Login(ctx context.Context, email, password, master string) (*AuthResponse, error) {
 	body := map[string]string{"email": email, "password": password, "master_password": master}
-	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest("POST", BackendURL+"/api/v1/auth/login", bytes.NewReader(b))
-	req.Header.Set("Content-Type", "application/json")
-	return doRequest(req)
+	req, err := c.newRequest(ctx, "POST", "/api/v1/auth/login", body)
+	if err != nil {
+		return nil, err
+	}
+	var out AuthResponse
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	if out.Token == "" {
+		return nil, fmt.Errorf("login: no token in response")
+	}
+	return &out, nil
 }
 
 // Secrets helpers
-func GetSecrets(page, limit int) (*http.Response, error) {
-	url := fmt.Sprintf("%s/api/v1/secrets?page=%d&limit=%d", BackendURL, page, limit)
-	req, _ := http.NewRequest("GET", url, nil)
-	return doRequest(req)
+func (c *Client) GetSecrets(ctx context.Context, page, limit int) (*SecretList, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("/api/v1/secrets?page=%d&limit=%d", page, limit), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		SecretList
+		Pagination *Page `json:"pagination"`
+	}
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	normalizePage(&out.Page, out.Pagination, page, limit, len(out.Secrets))
+	return &out.SecretList, nil
 }
 
-func CreateSecret(name, value, category, description, master string) (*http.Response, error) {
+// GetSecret fetches a single secret. When master is non-empty it is sent as
+// X-Master-Password and the decrypted Value is included in the result.
+func (c *Client) GetSecret(ctx context.Context, id, master string) (*Secret, error) {
+	req, err := c.newRequest(ctx, "GET", "/api/v1/secrets/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if master != "" {
+		req.Header.Set("X-Master-Password", master)
+	}
+	var out Secret
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AllSecrets walks every page of GetSecrets and returns the combined list. It
+// stops at the first short page, so a backend without pagination metadata is
+// still read to the end.
+func (c *Client) AllSecrets(ctx context.Context) ([]Secret, error) {
+	var all []Secret
+	for page := 1; ; page++ {
+		list, err := c.GetSecrets(ctx, page, 100)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, list.Secrets...)
+		if !list.HasNext() || len(list.Secrets) < list.Limit || len(list.Secrets) == 0 {
+			return all, nil
+		}
+	}
+}
+
+func (c *Client) CreateSecret(ctx context.Context, name, value, category, description, master string) (*Secret, error) {
 	body := map[string]string{"name": name, "value": value, "category": category, "description": description}
-	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest("POST", BackendURL+"/api/v1/secrets", bytes.NewReader(b))
-	req.Header.Set("Content-Type", "application/json")
+	req, err := c.newRequest(ctx, "POST", "/api/v1/secrets", body)
+	if err != nil {
+		return nil, err
+	}
+	setIdempotencyKey(req)
 	req.Header.Set("X-Master-Password", master)
-	return doRequest(req)
+	var out Secret
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
 }
 
-func UpdateSecret(id string, name, value, category, description, master string) (*http.Response, error) {
+func (c *Client) UpdateSecret(ctx context.Context, id string, name, value, category, description, master string) (*Secret, error) {
 	body := map[string]string{"name": name, "value": value, "category": category, "description": description}
-	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest("PUT", BackendURL+"/api/v1/secrets/"+id, bytes.NewReader(b))
-	req.Header.Set("Content-Type", "application/json")
+	req, err := c.newRequest(ctx, "PUT", "/api/v1/secrets/"+url.PathEscape(id), body)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Set("X-Master-Password", master)
-	return doRequest(req)
+	var out Secret
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
 }
 
-func DeleteSecret(id string) (*http.Response, error) {
-	req, _ := http.NewRequest("DELETE", BackendURL+"/api/v1/secrets/"+id, nil)
-	return doRequest(req)
+func (c *Client) DeleteSecret(ctx context.Context, id string) error {
+	req, err := c.newRequest(ctx, "DELETE", "/api/v1/secrets/"+url.PathEscape(id), nil)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
 }
 
 // API Keys helpers
-func GetAPIKeys(page, limit int, status string) (*http.Response, error) {
-	url := fmt.Sprintf("%s/api/v1/apikeys?page=%d&limit=%d", BackendURL, page, limit)
+func (c *Client) GetAPIKeys(ctx context.Context, page, limit int, status string) (*APIKeyList, error) {
+	path := fmt.Sprintf("/api/v1/apikeys?page=%d&limit=%d", page, limit)
 	if status != "" {
-		url = url + "&status=" + status
+		path = path + "&status=" + url.QueryEscape(status)
 	}
-	req, _ := http.NewRequest("GET", url, nil)
-	return doRequest(req)
+	req, err := c.newRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		APIKeyList
+		Pagination *Page `json:"pagination"`
+	}
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	normalizePage(&out.Page, out.Pagination, page, limit, len(out.APIKeys))
+	return &out.APIKeyList, nil
 }
 
-func CreateAPIKey(name string) (*http.Response, error) {
+func (c *Client) CreateAPIKey(ctx context.Context, name string) (*APIKey, error) {
 	body := map[string]string{"name": name}
-	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest("POST", BackendURL+"/api/v1/apikeys", bytes.NewReader(b))
-	req.Header.Set("Content-Type", "application/json")
-	return doRequest(req)
+	req, err := c.newRequest(ctx, "POST", "/api/v1/apikeys", body)
+	if err != nil {
+		return nil, err
+	}
+	setIdempotencyKey(req)
+	var out APIKey
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	req, err := c.newRequest(ctx, "POST", "/api/v1/apikeys/"+url.PathEscape(id)+"/revoke", nil)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
 }
 
-func RevokeAPIKey(id string) (*http.Response, error) {
-	req, _ := http.NewRequest("POST", BackendURL+"/api/v1/apikeys/"+id+"/revoke", nil)
-	return doRequest(req)
+// GetCurrentUser calls the backend /auth/me endpoint and returns the user behind the current token (or API key).
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	req, err := c.newRequest(ctx, "GET", "/api/v1/auth/me", nil)
+	if err != nil {
+		return nil, err
+	}
+	var out User
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
 }
 
 // GetCurrentUserEmail calls the backend /auth/me endpoint and returns the user's email if the current token (or API key) is valid.
-func GetCurrentUserEmail() (string, error) {
-	req, _ := http.NewRequest("GET", BackendURL+"/api/v1/auth/me", nil)
-	resp, err := doRequest(req)
+func (c *Client) GetCurrentUserEmail(ctx context.Context) (string, error) {
+	u, err := c.GetCurrentUser(ctx)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-	b, _ := ioutil.ReadAll(resp.Body)
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("invalid response: %s", string(b))
-	}
-	var out map[string]interface{}
-	json.Unmarshal(b, &out)
-	if em, ok := out["email"].(string); ok {
-		return em, nil
+	if u.Email == "" {
+		return "", fmt.Errorf("no email in response")
 	}
-	return "", fmt.Errorf("no email in response")
+	return u.Email, nil
 }
 
-// ValidateAPIKey will attempt to validate the provided API key by temporarily setting it as the client token
-// and calling the backend for the current user. If valid, token is kept and the user's email is returned.
-func ValidateAPIKey(key string) (string, error) {
-	prev := token
-	token = key
-	email, err := GetCurrentUserEmail()
+// ValidateAPIKey checks key against the backend for the current user. If valid,
+// the key becomes the client token and the user's email is returned; on failure
+// the existing token is left untouched.
+func (c *Client) ValidateAPIKey(ctx context.Context, key string) (string, error) {
+	email, err := c.withToken(key).GetCurrentUserEmail(ctx)
 	if err != nil {
-		// restore previous token on failure
-		token = prev
 		return "", err
 	}
-	// keep token set to the validated key
+	c.SetToken(key)
 	return email, nil
 }
-
-func HasToken() bool {
-	return token != ""
-}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	defaultMu     sync.RWMutex
+	defaultClient = NewClient()
+)
+
+// Default returns the client used by the package-level helpers.
+func Default() *Client {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultClient
+}
+
+// SetDefault replaces the client used by the package-level helpers.
+func SetDefault(c *Client) {
+	defaultMu.Lock()
+	defaultClient = c
+	defaultMu.Unlock()
+}
+
+// The helpers below are thin wrappers around Default() using
+// context.Background(); use the Client methods directly for cancellation and
+// per-call deadlines.
+
+func SetToken(t string) {
+	Default().SetToken(t)
+}
+
+func HasToken() bool {
+	return Default().HasToken()
+}
+
+func Health() (*HealthStatus, error) {
+	return Default().Health(context.Background())
+}
+
+func Signup(email, password, master string) (*AuthResponse, error) {
+	return Default().Signup(context.Background(), email, password, master)
+}
+
+func Login(email, password, master string) (*AuthResponse, error) {
+	return Default().Login(context.Background(), email, password, master)
+}
+
+func GetSecrets(page, limit int) (*SecretList, error) {
+	return Default().GetSecrets(context.Background(), page, limit)
+}
+
+func GetSecret(id, master string) (*Secret, error) {
+	return Default().GetSecret(context.Background(), id, master)
+}
+
+func CreateSecret(name, value, category, description, master string) (*Secret, error) {
+	return Default().CreateSecret(context.Background(), name, value, category, description, master)
+}
+
+func UpdateSecret(id string, name, value, category, description, master string) (*Secret, error) {
+	return Default().UpdateSecret(context.Background(), id, name, value, category, description, master)
+}
+
+func DeleteSecret(id string) error {
+	return Default().DeleteSecret(context.Background(), id)
+}
+
+func GetAPIKeys(page, limit int, status string) (*APIKeyList, error) {
+	return Default().GetAPIKeys(context.Background(), page, limit, status)
+}
+
+func CreateAPIKey(name string) (*APIKey, error) {
+	return Default().CreateAPIKey(context.Background(), name)
+}
+
+func RevokeAPIKey(id string) error {
+	return Default().RevokeAPIKey(context.Background(), id)
+}
+
+func GetCurrentUser() (*User, error) {
+	return Default().GetCurrentUser(context.Background())
+}
+
+func GetCurrentUserEmail() (string, error) {
+	return Default().GetCurrentUserEmail(context.Background())
+}
+
+func ValidateAPIKey(key string) (string, error) {
+	return Default().ValidateAPIKey(context.Background(), key)
+}
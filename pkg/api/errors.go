@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors matched by *Error through errors.Is.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// Error is returned for any non-2xx response from the backend.
+type Error struct {
+	StatusCode int
+	Code       string // backend error code, if any
+	Message    string // backend error message, or the raw body
+	Method     string
+	Path       string
+	RequestID  string
+	RetryAfter time.Duration // parsed Retry-After header, zero if absent
+
+	// IdempotencyKey is the key sent with a failed create. Pass it back via
+	// WithIdempotencyKey when retrying so the operation is not applied twice.
+	IdempotencyKey string
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Code != "" {
+		b.WriteString(" [" + e.Code + "]")
+	}
+	if e.Message != "" {
+		b.WriteString(": " + e.Message)
+	}
+	if e.RequestID != "" {
+		b.WriteString(" (request " + e.RequestID + ")")
+	}
+	return b.String()
+}
+
+// Is maps the HTTP status onto the package sentinel errors.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Temporary reports whether the request may succeed if retried later.
+func (e *Error) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// newError builds an *Error from a failed response and its already-read body.
+func newError(req *http.Request, resp *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RequestID:  resp.Header.Get("X-Request-ID"),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+
+		IdempotencyKey: req.Header.Get(idempotencyHeader),
+	}
+
+	// the backend answers either {"error": "msg"} or {"error": {"code", "message"}},
+	// optionally with top-level code/message/request_id
+	var payload struct {
+		Error     json.RawMessage `json:"error"`
+		Code      string          `json:"code"`
+		Message   string          `json:"message"`
+		RequestID string          `json:"request_id"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		e.Message = strings.TrimSpace(string(body))
+		return e
+	}
+	e.Code = payload.Code
+	e.Message = payload.Message
+	if e.RequestID == "" {
+		e.RequestID = payload.RequestID
+	}
+	if len(payload.Error) > 0 {
+		var msg string
+		var nested struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(payload.Error, &msg) == nil {
+			if e.Message == "" {
+				e.Message = msg
+			}
+		} else if json.Unmarshal(payload.Error, &nested) == nil {
+			if nested.Code != "" {
+				e.Code = nested.Code
+			}
+			if nested.Message != "" {
+				e.Message = nested.Message
+			}
+		}
+	}
+	return e
+}
+
+// parseRetryAfter accepts both delta-seconds and HTTP-date forms.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
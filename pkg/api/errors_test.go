@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		header    http.Header
+		code      string
+		message   string
+		requestID string
+	}{
+		{
+			name:    "string error",
+			status:  401,
+			body:    `{"error": "invalid token"}`,
+			message: "invalid token",
+		},
+		{
+			name:    "nested error",
+			status:  404,
+			body:    `{"error": {"code": "not_found", "message": "secret not found"}}`,
+			code:    "not_found",
+			message: "secret not found",
+		},
+		{
+			name:      "top-level fields",
+			status:    409,
+			body:      `{"code": "conflict", "message": "name taken", "request_id": "r1"}`,
+			code:      "conflict",
+			message:   "name taken",
+			requestID: "r1",
+		},
+		{
+			name:      "header request id wins",
+			status:    500,
+			body:      `{"message": "boom", "request_id": "body"}`,
+			header:    http.Header{"X-Request-Id": {"hdr"}},
+			message:   "boom",
+			requestID: "hdr",
+		},
+		{
+			name:    "plain text body",
+			status:  502,
+			body:    "  bad gateway\n",
+			message: "bad gateway",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/secrets", nil)
+			resp := &http.Response{StatusCode: tt.status, Header: tt.header}
+			if resp.Header == nil {
+				resp.Header = http.Header{}
+			}
+			e := newError(req, resp, []byte(tt.body))
+			if e.Code != tt.code || e.Message != tt.message || e.RequestID != tt.requestID {
+				t.Errorf("got code %q, message %q, request %q; want %q, %q, %q",
+					e.Code, e.Message, e.RequestID, tt.code, tt.message, tt.requestID)
+			}
+		})
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	for status, target := range map[int]error{
+		401: ErrUnauthorized,
+		404: ErrNotFound,
+		409: ErrConflict,
+		429: ErrRateLimited,
+	} {
+		err := error(&Error{StatusCode: status})
+		if !errors.Is(err, target) {
+			t.Errorf("status %d is not %v", status, target)
+		}
+		if errors.Is(&Error{StatusCode: 500}, target) {
+			t.Errorf("status 500 matches %v", target)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("120"); d != 2*time.Minute {
+		t.Errorf("parseRetryAfter(120) = %v", d)
+	}
+	for _, v := range []string{"", "0", "-5", "soon"} {
+		if d := parseRetryAfter(v); d != 0 {
+			t.Errorf("parseRetryAfter(%q) = %v, want 0", v, d)
+		}
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(date); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %v, want about an hour", date, d)
+	}
+}
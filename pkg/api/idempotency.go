@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type idempotencyKeyCtx struct{}
+
+// NewIdempotencyKey returns a random UUIDv4 suitable for the Idempotency-Key
+// header.
+func NewIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("api: reading random bytes: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// WithIdempotencyKey returns a context that makes create calls (CreateSecret,
+// CreateAPIKey, Signup) send key instead of a freshly generated one. Re-running
+// a create with the same key after a timeout lets the backend return the
+// original result rather than creating a duplicate.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key set by WithIdempotencyKey.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key, ok && key != ""
+}
+
+// setIdempotencyKey attaches the context key, or a new one, to req. The header
+// survives rewind, so every retry of req carries the same key.
+func setIdempotencyKey(req *http.Request) {
+	key, ok := IdempotencyKeyFromContext(req.Context())
+	if !ok {
+		key = NewIdempotencyKey()
+	}
+	req.Header.Set(idempotencyHeader, key)
+}
+
+// KeyedError wraps a failed create that got no usable answer from the
+// backend, such as a timeout, with the Idempotency-Key it was sent with.
+// Failures the backend did answer are *Error, which carries the key too.
+type KeyedError struct {
+	IdempotencyKey string
+	Err            error
+}
+
+func (e *KeyedError) Error() string { return e.Err.Error() }
+func (e *KeyedError) Unwrap() error { return e.Err }
+
+// IdempotencyKeyOf returns the key of the create call that failed with err,
+// so it can be retried through WithIdempotencyKey without a duplicate.
+func IdempotencyKeyOf(err error) (string, bool) {
+	var keyed *KeyedError
+	if errors.As(err, &keyed) {
+		return keyed.IdempotencyKey, true
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.IdempotencyKey != "" {
+		return apiErr.IdempotencyKey, true
+	}
+	return "", false
+}
+
+// withKey wraps err in a KeyedError when req carries an Idempotency-Key.
+func withKey(req *http.Request, err error) error {
+	if key := req.Header.Get(idempotencyHeader); key != "" {
+		return &KeyedError{IdempotencyKey: key, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+// keyRecorder fails the first n requests with 503 and records the
+// Idempotency-Key of every request.
+type keyRecorder struct {
+	mu   sync.Mutex
+	fail int
+	keys []string
+}
+
+func (k *keyRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = append(k.keys, r.Header.Get(idempotencyHeader))
+	if len(k.keys) <= k.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte(`{"id": "s1", "name": "db"}`))
+}
+
+func TestCreateRetriesReuseIdempotencyKey(t *testing.T) {
+	rec := &keyRecorder{fail: 2}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), fastRetries)
+	if _, err := c.CreateSecret(context.Background(), "db", "v", "", "", "m"); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.keys) != 3 {
+		t.Fatalf("sent %d requests, want 3", len(rec.keys))
+	}
+	for _, k := range rec.keys {
+		if k == "" || k != rec.keys[0] {
+			t.Fatalf("keys differ between attempts: %q", rec.keys)
+		}
+	}
+}
+
+func TestCreateUsesContextIdempotencyKey(t *testing.T) {
+	rec := &keyRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"))
+	ctx := WithIdempotencyKey(context.Background(), "retry-me")
+	if _, err := c.CreateSecret(ctx, "db", "v", "", "", "m"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateSecret(context.Background(), "db", "v", "", "", "m"); err != nil {
+		t.Fatal(err)
+	}
+	if rec.keys[0] != "retry-me" {
+		t.Errorf("key = %q, want the one from the context", rec.keys[0])
+	}
+	if rec.keys[1] == "" || rec.keys[1] == "retry-me" {
+		t.Errorf("second create reused key %q", rec.keys[1])
+	}
+}
+
+func TestCreateFailureCarriesIdempotencyKey(t *testing.T) {
+	rec := &keyRecorder{fail: 10}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), fastRetries)
+	_, err := c.CreateSecret(context.Background(), "db", "v", "", "", "m")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %T %v, want *Error", err, err)
+	}
+	if apiErr.IdempotencyKey == "" || apiErr.IdempotencyKey != rec.keys[0] {
+		t.Errorf("IdempotencyKey = %q, sent %q", apiErr.IdempotencyKey, rec.keys[0])
+	}
+}
+
+func TestTransportFailureCarriesIdempotencyKey(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent = append(sent, r.Header.Get(idempotencyHeader))
+		mu.Unlock()
+		// drop the connection without an answer
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"), fastRetries)
+	_, err := c.CreateSecret(context.Background(), "db", "v", "", "", "m")
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Fatalf("err = %T %v, want a *url.Error", err, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	key, ok := IdempotencyKeyOf(err)
+	if !ok || len(sent) == 0 || key != sent[0] {
+		t.Errorf("IdempotencyKeyOf = %q, %v; sent %q", key, ok, sent)
+	}
+	if _, ok := IdempotencyKeyOf(urlErr); ok {
+		t.Error("IdempotencyKeyOf found a key on a bare transport error")
+	}
+}
+
+func TestNewIdempotencyKey(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	a, b := NewIdempotencyKey(), NewIdempotencyKey()
+	if !uuid.MatchString(a) || a == b {
+		t.Errorf("keys %q, %q: want two distinct UUIDv4s", a, b)
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotJWT is returned by ParseToken for opaque tokens such as API keys.
+var ErrNotJWT = errors.New("token is not a JWT")
+
+// Claims are the registered and backend-specific JWT claims the CLI uses.
+// The signature is not verified; the backend does that on every request.
+type Claims struct {
+	Subject   string
+	Email     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time // zero when the token carries no exp
+}
+
+// ParseToken decodes the payload of a JWT without verifying it.
+func ParseToken(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrNotJWT
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, ErrNotJWT
+	}
+	var raw struct {
+		Sub   string  `json:"sub"`
+		Email string  `json:"email"`
+		Iat   float64 `json:"iat"`
+		Exp   float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, ErrNotJWT
+	}
+	c := &Claims{Subject: raw.Sub, Email: raw.Email}
+	if raw.Iat > 0 {
+		c.IssuedAt = time.Unix(int64(raw.Iat), 0)
+	}
+	if raw.Exp > 0 {
+		c.ExpiresAt = time.Unix(int64(raw.Exp), 0)
+	}
+	return c, nil
+}
+
+// Expired reports whether the token is past its exp claim.
+func (c *Claims) Expired() bool {
+	return !c.ExpiresAt.IsZero() && time.Now().After(c.ExpiresAt)
+}
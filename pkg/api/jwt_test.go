@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func jwt(payload string) string {
+	return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestParseToken(t *testing.T) {
+	c, err := ParseToken(jwt(`{"sub": "u1", "email": "a@b.c", "iat": 1700000000, "exp": 1700003600}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject != "u1" || c.Email != "a@b.c" {
+		t.Errorf("got subject %q, email %q", c.Subject, c.Email)
+	}
+	if !c.IssuedAt.Equal(time.Unix(1700000000, 0)) || !c.ExpiresAt.Equal(time.Unix(1700003600, 0)) {
+		t.Errorf("got iat %v, exp %v", c.IssuedAt, c.ExpiresAt)
+	}
+	if !c.Expired() {
+		t.Error("token from 2023 is not expired")
+	}
+}
+
+func TestParseTokenNoExpiry(t *testing.T) {
+	c, err := ParseToken(jwt(`{"sub": "u1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.ExpiresAt.IsZero() || c.Expired() {
+		t.Errorf("token without exp: ExpiresAt %v, Expired %v", c.ExpiresAt, c.Expired())
+	}
+}
+
+func TestParseTokenNotJWT(t *testing.T) {
+	for _, tok := range []string{
+		"sk_live_abcdef",
+		"a.b",
+		"a.!!!.c",
+		jwt(`not json`),
+	} {
+		if _, err := ParseToken(tok); !errors.Is(err, ErrNotJWT) {
+			t.Errorf("ParseToken(%q): err = %v, want ErrNotJWT", tok, err)
+		}
+	}
+}
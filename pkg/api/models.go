@@ -0,0 +1,101 @@
+package api
+
+import "time"
+
+// Secret is a single secret as stored by the vault backend. Value is only
+// populated by endpoints that decrypt it with the master password.
+type Secret struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Value       string    `json:"value,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// APIKey describes an API key. Key holds the full key and is only returned
+// once, by CreateAPIKey.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix,omitempty"`
+	Key        string     `json:"key,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// User is the account behind the current token or API key.
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuthResponse is returned by Signup and Login.
+type AuthResponse struct {
+	Token string `json:"token"`
+	User  *User  `json:"user,omitempty"`
+}
+
+// HealthStatus is the body of the backend /health endpoint.
+type HealthStatus struct {
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"`
+}
+
+// Page is the pagination metadata attached to list responses.
+type Page struct {
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// HasNext reports whether another page follows this one.
+func (p Page) HasNext() bool {
+	return p.Page < p.TotalPages
+}
+
+// SecretList is one page of secrets.
+type SecretList struct {
+	Secrets []Secret `json:"secrets"`
+	Page
+}
+
+// APIKeyList is one page of API keys.
+type APIKeyList struct {
+	APIKeys []APIKey `json:"api_keys"`
+	Page
+}
+
+// normalizePage fills in pagination fields the backend left out, either from
+// a nested "pagination" object or from the request parameters. Without any
+// metadata a full page may be followed by more, so Total and TotalPages then
+// only count what has been seen, plus one page when count reached limit.
+func normalizePage(p *Page, nested *Page, page, limit, count int) {
+	if nested != nil {
+		*p = *nested
+	}
+	if p.Page == 0 {
+		p.Page = page
+	}
+	if p.Limit == 0 {
+		p.Limit = limit
+	}
+	if p.Total == 0 && p.TotalPages == 0 {
+		p.Total = (p.Page-1)*p.Limit + count
+		p.TotalPages = p.Page
+		if p.Limit > 0 && count == p.Limit {
+			p.TotalPages++
+		}
+	}
+	if p.TotalPages == 0 && p.Limit > 0 {
+		p.TotalPages = (p.Total + p.Limit - 1) / p.Limit
+	}
+	if p.TotalPages == 0 {
+		p.TotalPages = 1
+	}
+}
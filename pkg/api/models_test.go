@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestNormalizePage(t *testing.T) {
+	tests := []struct {
+		name               string
+		got                Page
+		nested             *Page
+		page, limit, count int
+		want               Page
+		next               bool
+	}{
+		{"full metadata", Page{Page: 2, Limit: 10, Total: 35, TotalPages: 4}, nil, 2, 10, 10,
+			Page{2, 10, 35, 4}, true},
+		{"nested metadata", Page{}, &Page{Page: 4, Limit: 10, Total: 35}, 4, 10, 5,
+			Page{4, 10, 35, 4}, false},
+		{"no metadata, full page", Page{}, nil, 1, 100, 100,
+			Page{1, 100, 100, 2}, true},
+		{"no metadata, full second page", Page{}, nil, 2, 100, 100,
+			Page{2, 100, 200, 3}, true},
+		{"no metadata, short page", Page{}, nil, 2, 100, 5,
+			Page{2, 100, 105, 2}, false},
+		{"no metadata, empty", Page{}, nil, 1, 20, 0,
+			Page{1, 20, 0, 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.got
+			normalizePage(&p, tt.nested, tt.page, tt.limit, tt.count)
+			if p != tt.want || p.HasNext() != tt.next {
+				t.Errorf("got %+v (next %v), want %+v (next %v)", p, p.HasNext(), tt.want, tt.next)
+			}
+		})
+	}
+}
+
+// pagedSecrets serves n secrets in pages without any pagination metadata.
+func pagedSecrets(n int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		list := []Secret{}
+		for i := (page - 1) * limit; i < n && i < page*limit; i++ {
+			list = append(list, Secret{ID: fmt.Sprint(i), Name: fmt.Sprint("s", i)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"secrets": list})
+	})
+}
+
+func TestAllSecretsWithoutMetadata(t *testing.T) {
+	for _, n := range []int{0, 5, 100, 105, 200} {
+		srv := httptest.NewServer(pagedSecrets(n))
+		c := NewClient(WithBaseURL(srv.URL), WithToken("t"))
+		all, err := c.AllSecrets(context.Background())
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != n {
+			t.Errorf("%d secrets on the server, AllSecrets returned %d", n, len(all))
+		}
+	}
+}
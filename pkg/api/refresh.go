@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Refresher obtains a replacement for a token the backend rejected with 401.
+type Refresher func(ctx context.Context, c *Client, rejected string) (string, error)
+
+// WithRefresher replaces the default refresh strategy (RefreshEndpoint).
+// Passing nil disables automatic refresh.
+func WithRefresher(r Refresher) Option {
+	return func(c *Client) {
+		c.refresher = r
+	}
+}
+
+// WithTokenObserver registers fn to be called after a token was refreshed,
+// e.g. to persist it.
+func WithTokenObserver(fn func(token string)) Option {
+	return func(c *Client) {
+		c.onToken = fn
+	}
+}
+
+// RefreshEndpoint is the default Refresher: it trades the rejected token for
+// a new one at /api/v1/auth/refresh.
+func RefreshEndpoint(ctx context.Context, c *Client, rejected string) (string, error) {
+	auth, err := c.withToken(rejected).Refresh(ctx)
+	if err != nil {
+		return "", err
+	}
+	return auth.Token, nil
+}
+
+// Refresh asks the backend for a new JWT in exchange for the current one.
+func (c *Client) Refresh(ctx context.Context) (*AuthResponse, error) {
+	req, err := c.newRequest(ctx, "POST", "/api/v1/auth/refresh", nil)
+	if err != nil {
+		return nil, err
+	}
+	var out AuthResponse
+	if err := c.send(req, &out); err != nil {
+		return nil, err
+	}
+	if out.Token == "" {
+		return nil, errors.New("refresh: no token in response")
+	}
+	return &out, nil
+}
+
+// TokenClaims decodes the current token. It returns ErrNotJWT for API keys.
+func (c *Client) TokenClaims() (*Claims, error) {
+	return ParseToken(c.Token())
+}
+
+// canRefresh reports whether a 401 for req should trigger a refresh: only JWTs
+// expire, and auth endpoints must not recurse into refreshing.
+func (c *Client) canRefresh(req *http.Request) bool {
+	switch req.URL.Path {
+	case "/api/v1/auth/login", "/api/v1/auth/signup", "/api/v1/auth/refresh":
+		return false
+	}
+	if c.refresher == nil {
+		return false
+	}
+	_, err := ParseToken(bearer(req))
+	return err == nil
+}
+
+// refreshToken replaces rejected with a fresh token. Concurrent callers that
+// hit 401 with the same token share one refresh.
+func (c *Client) refreshToken(ctx context.Context, rejected string) (string, error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	if cur := c.Token(); cur != rejected && cur != "" {
+		return cur, nil
+	}
+	token, err := c.refresher(ctx, c, rejected)
+	if err != nil {
+		return "", err
+	}
+	c.SetToken(token)
+	if c.onToken != nil {
+		c.onToken(token)
+	}
+	return token, nil
+}
+
+func bearer(req *http.Request) string {
+	return strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// refreshServer accepts only its fresh token on /api/v1/secrets and hands
+// it out from /api/v1/auth/refresh, unless reject is set.
+type refreshServer struct {
+	fresh     string
+	reject    bool
+	calls     int32
+	refreshes int32
+}
+
+func (s *refreshServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v1/auth/refresh":
+		atomic.AddInt32(&s.refreshes, 1)
+		w.Write([]byte(`{"token": "` + s.fresh + `"}`))
+	default:
+		atomic.AddInt32(&s.calls, 1)
+		if s.reject || r.Header.Get("Authorization") != "Bearer "+s.fresh {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"secrets": []}`))
+	}
+}
+
+func TestRefreshOnUnauthorized(t *testing.T) {
+	srv := &refreshServer{fresh: jwt(`{"sub": "u1", "exp": 4102444800}`)}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var observed string
+	c := NewClient(WithBaseURL(ts.URL), WithToken(jwt(`{"sub": "u1", "exp": 1}`)),
+		WithTokenObserver(func(tok string) { observed = tok }))
+	if _, err := c.GetSecrets(context.Background(), 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	if srv.calls != 2 || srv.refreshes != 1 {
+		t.Errorf("%d calls, %d refreshes; want 2 and 1", srv.calls, srv.refreshes)
+	}
+	if c.Token() != srv.fresh || observed != srv.fresh {
+		t.Errorf("token not replaced: client %q, observer %q", c.Token(), observed)
+	}
+}
+
+func TestRefreshReplaysOnce(t *testing.T) {
+	srv := &refreshServer{fresh: jwt(`{"sub": "u1"}`), reject: true}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithToken(jwt(`{"sub": "u1", "exp": 1}`)))
+	_, err := c.GetSecrets(context.Background(), 1, 10)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+	if srv.calls != 2 || srv.refreshes != 1 {
+		t.Errorf("%d calls, %d refreshes; want 2 and 1", srv.calls, srv.refreshes)
+	}
+}
+
+func TestNoRefreshForAPIKeys(t *testing.T) {
+	srv := &refreshServer{fresh: jwt(`{"sub": "u1"}`)}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithToken("sk_live_key"))
+	if _, err := c.GetSecrets(context.Background(), 1, 10); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+	if srv.calls != 1 || srv.refreshes != 0 {
+		t.Errorf("%d calls, %d refreshes; want 1 and 0", srv.calls, srv.refreshes)
+	}
+}
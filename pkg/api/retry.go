@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const idempotencyHeader = "Idempotency-Key"
+
+// RetryPolicy controls how failed requests are retried. Only idempotent
+// methods (GET, HEAD, PUT, DELETE) are retried, plus POSTs that carry an
+// Idempotency-Key header.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first; 1 disables retries
+	BaseDelay   time.Duration // delay before the first retry, doubled each time
+	MaxDelay    time.Duration // upper bound for a single backoff delay
+}
+
+// DefaultRetryPolicy is used by clients created without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// WithRetryPolicy replaces the client's retry policy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithMaxAttempts changes only the number of attempts of the retry policy.
+func WithMaxAttempts(n int) Option {
+	return func(c *Client) {
+		c.retry.MaxAttempts = n
+	}
+}
+
+// backoff returns the delay before retry number attempt (1-based). A
+// Retry-After sent with 429 or 503 takes precedence over the computed delay;
+// when it asks for longer than MaxDelay, ok is false and the caller should
+// give up rather than block.
+func (p RetryPolicy) backoff(attempt int, err error) (d time.Duration, ok bool) {
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 &&
+		(apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusServiceUnavailable) {
+		if p.MaxDelay > 0 && apiErr.RetryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return apiErr.RetryAfter, true
+	}
+	d = p.BaseDelay << (attempt - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0, true
+	}
+	// jitter in [d/2, d)
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int64N(half+1)), true
+}
+
+// retryable reports whether req may safely be sent more than once.
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return req.Header.Get(idempotencyHeader) != ""
+}
+
+// rewind returns a copy of req with a fresh body so it can be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	plain := errors.New("connection reset")
+	for attempt, max := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		5:  time.Second, // capped
+		70: time.Second, // shift overflow
+	} {
+		d, ok := p.backoff(attempt, plain)
+		if !ok || d < max/2 || d > max {
+			t.Errorf("backoff(%d) = %v, %v; want within [%v, %v]", attempt, d, ok, max/2, max)
+		}
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		status int
+		after  time.Duration
+		want   time.Duration
+		ok     bool
+	}{
+		{http.StatusTooManyRequests, 2 * time.Second, 2 * time.Second, true},
+		{http.StatusServiceUnavailable, 5 * time.Second, 5 * time.Second, true},
+		{http.StatusTooManyRequests, 24 * time.Hour, 0, false},
+		{http.StatusBadGateway, 24 * time.Hour, -1, true}, // ignored, computed delay
+	}
+	for _, tt := range tests {
+		err := &Error{StatusCode: tt.status, RetryAfter: tt.after}
+		d, ok := p.backoff(1, err)
+		if ok != tt.ok || (tt.want >= 0 && d != tt.want) || (tt.want < 0 && d > p.BaseDelay) {
+			t.Errorf("status %d, Retry-After %v: got %v, %v; want %v, %v", tt.status, tt.after, d, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSendGivesUpOnLongRetryAfter(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"))
+	start := time.Now()
+	_, err := c.GetSecrets(context.Background(), 1, 10)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("sent %d requests, want 1", n)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("took %v, want an immediate error", time.Since(start))
+	}
+}
+
+func TestSendRetriesTemporaryErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"secrets": []}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithToken("t"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	if _, err := c.GetSecrets(context.Background(), 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("sent %d requests, want 3", n)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tt := range []struct {
+		method string
+		key    string
+		want   bool
+	}{
+		{"GET", "", true},
+		{"PUT", "", true},
+		{"DELETE", "", true},
+		{"POST", "", false},
+		{"POST", "k", true},
+		{"PATCH", "", false},
+	} {
+		req := httptest.NewRequest(tt.method, "/", strings.NewReader(""))
+		if tt.key != "" {
+			req.Header.Set(idempotencyHeader, tt.key)
+		}
+		if got := retryable(req); got != tt.want {
+			t.Errorf("retryable(%s, key %q) = %v, want %v", tt.method, tt.key, got, tt.want)
+		}
+	}
+}
@@ -1,19 +1,21 @@
 package app
 
 import (
+	"context"
 	"fmt"
-	"net/http"
-	"time"
 
 	"sm-cli/pkg/api"
+	"sm-cli/pkg/config"
+	"sm-cli/pkg/credstore"
 	"sm-cli/pkg/ui"
 
 	"github.com/gdamore/tcell/v2"
 )
 
-var backendURL = "http://localhost:8080"
-
-func Run() error {
+// Run starts the TUI against the backend described by cfg. The api default
+// client is expected to be configured from the same cfg; successful logins
+// are saved to store.
+func Run(cfg *config.Config, store credstore.Store) error {
 	s, err := tcell.NewScreen()
 	if err != nil {
 		return fmt.Errorf("failed to create tcell screen: %w", err)
@@ -22,19 +24,26 @@ func Run() error {
 		return fmt.Errorf("failed to init screen: %w", err)
 	}
 	defer s.Fini()
+	// bracketed paste lets editors tell pasted text from typed keys
+	s.EnablePaste()
 
 	s.Clear()
 	w, h := s.Size()
 
 	u := ui.New(s)
+	u.SetProfile(cfg.Profile)
+	u.SetDefaultCategory(cfg.DefaultCategory)
+	if fs, ok := store.(*credstore.FileStore); ok {
+		// the terminal is in raw mode now, ask through a form instead
+		fs.Passphrase = u.PromptPassphrase
+	}
+	u.SetCredentialStore(store)
 	u.DrawSplash(w, h)
 
 	// Non-blocking health check
 	go func() {
-		client := http.Client{Timeout: 3 * time.Second}
-		resp, _ := client.Get(backendURL + "/health")
-		if resp != nil {
-			resp.Body.Close()
+		if _, err := api.Default().Health(context.Background()); err != nil {
+			ui.DrawStatus(s, "Backend unreachable at "+cfg.BackendURL)
 		}
 	}()
 
@@ -122,6 +131,10 @@ func Run() error {
 					if !api.HasToken() {
 						u.ShowLogin()
 					}
+				case "Signup":
+					if !api.HasToken() {
+						u.ShowSignup()
+					}
 				case "Secrets":
 					if selFlags[selected] {
 						u.ShowSecretsList(1)
@@ -129,6 +142,12 @@ func Run() error {
 						// disabled: show warning
 						u.ShowDisabledWarning(sel)
 					}
+				case "API Keys":
+					if selFlags[selected] {
+						u.ShowAPIKeys(1)
+					} else {
+						u.ShowDisabledWarning(sel)
+					}
 				case "Help":
 					u.ShowHelp()
 				case "Quit":
@@ -151,6 +170,17 @@ func Run() error {
 			case 'l', 'L':
 				if !api.HasToken() {
 					u.ShowLogin()
+					u.RenderMainMenu(selected)
+				}
+			case 's', 'S':
+				if !api.HasToken() {
+					u.ShowSignup()
+					u.RenderMainMenu(selected)
+				}
+			case 'a', 'A':
+				if api.HasToken() {
+					u.ShowAPIKeys(1)
+					u.RenderMainMenu(selected)
 				}
 			case 'h', 'H':
 				u.ShowHelp()
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"sm-cli/pkg/api"
+)
+
+func (r *runner) apikeys(ctx context.Context, args []string) error {
+	sub, args, err := subcommand("apikeys", args, "list", "create", "revoke")
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		return r.apikeysList(ctx, args)
+	case "create":
+		return r.apikeysCreate(ctx, args)
+	default:
+		return r.apikeysRevoke(ctx, args)
+	}
+}
+
+func (r *runner) apikeysList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("apikeys list", flag.ContinueOnError)
+	page := fs.Int("page", 1, "page to fetch")
+	limit := fs.Int("limit", 20, "keys per page")
+	status := fs.String("status", "", "only show keys with this status (active, revoked)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	list, err := r.client.GetAPIKeys(ctx, *page, *limit, *status)
+	if err != nil {
+		return err
+	}
+	if err := r.out.print(list, apiKeyTable(list.APIKeys), nil); err != nil {
+		return err
+	}
+	if !r.out.structured() {
+		fmt.Fprintf(r.stderr, "page %d of %d (%d total)\n", list.Page.Page, list.TotalPages, list.Total)
+	}
+	return nil
+}
+
+func (r *runner) apikeysCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("apikeys create", flag.ContinueOnError)
+	idemKey := fs.String("idempotency-key", "", "reuse the key of an earlier, interrupted create")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("apikeys create: expected exactly one key name")
+	}
+	key := *idemKey
+	if key == "" {
+		key = api.NewIdempotencyKey()
+	}
+	k, err := r.client.CreateAPIKey(api.WithIdempotencyKey(ctx, key), pos[0])
+	if err != nil {
+		return fmt.Errorf("%w (retry with --idempotency-key %s)", err, key)
+	}
+	fmt.Fprintf(r.stderr, "Created API key %s (%s). It will not be shown again.\n", k.Name, k.ID)
+	if r.out.structured() {
+		return r.out.print(k, table{}, []envVar{{envName(k.Name), k.Key}})
+	}
+	fmt.Fprintln(r.stdout, k.Key)
+	return nil
+}
+
+func (r *runner) apikeysRevoke(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("apikeys revoke", flag.ContinueOnError)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("apikeys revoke: expected exactly one key id")
+	}
+	if err := r.client.RevokeAPIKey(ctx, pos[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.stdout, "Revoked API key %s\n", pos[0])
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return formatTime(*t)
+}
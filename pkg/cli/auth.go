@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"sm-cli/pkg/credstore"
+)
+
+func (r *runner) login(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	apiKey := fs.String("api-key", "", "API key to validate (prompted when neither flag is given)")
+	email := fs.String("email", "", "log in with email and password instead of an API key")
+	noSave := fs.Bool("no-save", false, "do not remember the token for later sessions")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *email != "" {
+		password, err := r.readSecret("Password")
+		if err != nil {
+			return err
+		}
+		master, err := r.readSecret("Master password")
+		if err != nil {
+			return err
+		}
+		auth, err := r.client.Login(ctx, *email, password, master)
+		if err != nil {
+			return err
+		}
+		r.client.SetToken(auth.Token)
+		fmt.Fprintln(r.stderr, "Logged in as", *email)
+		if !*noSave {
+			return r.saveToken(auth.Token)
+		}
+		// print the token so scripts can capture it for --token
+		fmt.Fprintln(r.stdout, auth.Token)
+		return nil
+	}
+
+	key := *apiKey
+	if key == "" {
+		var err error
+		if key, err = r.readSecret("API key"); err != nil {
+			return err
+		}
+	}
+	who, err := r.client.ValidateAPIKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(r.stdout, "Logged in as", who)
+	if *noSave {
+		return nil
+	}
+	return r.saveToken(key)
+}
+
+func (r *runner) saveToken(token string) error {
+	if err := r.store.Set(r.cfg.Profile, token); err != nil {
+		return fmt.Errorf("saving login: %w", err)
+	}
+	if r.store.Name() != credstore.BackendNone {
+		fmt.Fprintf(r.stderr, "Saved login for profile %s in %s\n", r.cfg.Profile, r.store.Name())
+	}
+	return nil
+}
+
+func (r *runner) logout(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("logout", flag.ContinueOnError)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := r.store.Delete(r.cfg.Profile); err != nil {
+		return fmt.Errorf("removing saved login: %w", err)
+	}
+	r.client.SetToken("")
+	fmt.Fprintf(r.stdout, "Logged out of profile %s\n", r.cfg.Profile)
+	return nil
+}
+
+func (r *runner) whoami(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("whoami", flag.ContinueOnError)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	u, err := r.client.GetCurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+	if r.out.format == "" {
+		_, err = fmt.Fprintln(r.stdout, u.Email)
+		return err
+	}
+	t := table{columns: []string{"id", "email"}, rows: []map[string]string{{"id": u.ID, "email": u.Email, "created": formatTime(u.CreatedAt)}}}
+	return r.out.print(u, t, []envVar{{"SM_USER_EMAIL", u.Email}})
+}
+
+func (r *runner) health(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	hs, err := r.client.Health(ctx)
+	if err != nil {
+		return err
+	}
+	if r.out.format == "" {
+		line := hs.Status
+		if hs.Version != "" {
+			line += " (" + hs.Version + ")"
+		}
+		_, err = fmt.Fprintf(r.stdout, "%s: %s\n", r.client.BaseURL(), line)
+		return err
+	}
+	t := table{columns: []string{"backend", "status", "version"}, rows: []map[string]string{{"backend": r.client.BaseURL(), "status": hs.Status, "version": hs.Version}}}
+	return r.out.print(hs, t, nil)
+}
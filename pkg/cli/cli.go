@@ -0,0 +1,250 @@
+// Package cli implements the non-interactive sm-cli subcommands. Running
+// without a subcommand (or with "tui") starts the terminal UI instead.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"sm-cli/pkg/api"
+	"sm-cli/pkg/app"
+	"sm-cli/pkg/config"
+	"sm-cli/pkg/credstore"
+)
+
+const usage = `Usage: sm-cli [global flags] [command] [args]
+
+Commands:
+  tui                                   start the terminal UI (default)
+  login [--api-key KEY | --email EMAIL] log in and remember the token
+  logout                                forget the saved token
+  whoami                                print the current user's email
+  health                                check the backend
+  secrets list|get|create|update|delete manage secrets
+  apikeys list|create|revoke            manage API keys
+  profile list|use|add|remove           manage configuration profiles
+  run --secret ENV=NAME -- CMD [ARGS]   run CMD with secrets in its environment
+  render [--strict|--check] TEMPLATE    render a text/template with {{ secret "name" }}
+  import [--dry-run] FILE               import secrets from .env, JSON, YAML or CSV
+
+Global flags:
+  --backend URL       Secrets Vault backend (env SM_BACKEND_URL)
+  --token TOKEN       bearer token or API key used for requests (env SM_API_KEY)
+  --profile NAME      configuration profile (env SM_PROFILE)
+  --config PATH       config file (default $XDG_CONFIG_HOME/sm-cli/config.yaml)
+  --output FORMAT     table, json, yaml or env (KEY=VALUE)
+  --columns a,b,c     columns to show with table output
+
+Exit codes: 0 ok, 1 error, 2 usage, 3 not found, 4 unauthorized,
+5 server error or backend unreachable.
+
+Run "sm-cli <command> -h" for command flags.
+`
+
+// errUsage marks errors caused by bad invocation.
+var errUsage = errors.New("usage error")
+
+var errNotLoggedIn = errors.New("not logged in: run \"sm-cli login\", pass --token or set SM_API_KEY")
+
+// runner carries the client and streams shared by every command.
+type runner struct {
+	cfg    *config.Config
+	client *api.Client
+	store  credstore.Store
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	out    *printer
+
+	lines        *bufio.Reader // buffered stdin for readLine
+	restored     bool          // restoreToken already ran
+	stdinDrained bool          // a command consumed all of stdin
+}
+
+type command struct {
+	name string
+	run  func(r *runner, ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"login", (*runner).login},
+	{"logout", (*runner).logout},
+	{"whoami", (*runner).whoami},
+	{"health", (*runner).health},
+	{"secrets", (*runner).secrets},
+	{"apikeys", (*runner).apikeys},
+	{"profile", (*runner).profile},
+	{"run", (*runner).run},
+	{"render", (*runner).render},
+	{"import", (*runner).importSecrets},
+}
+
+// Run parses args (without the program name) and executes the matching
+// command, or launches the TUI when none is given.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("sm-cli", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(fs.Output(), usage) }
+	var o config.Overrides
+	fs.StringVar(&o.BackendURL, "backend", "", "backend URL")
+	fs.StringVar(&o.APIKey, "token", "", "bearer token or API key")
+	fs.StringVar(&o.Profile, "profile", "", "configuration profile")
+	fs.StringVar(&o.Path, "config", "", "config file")
+	output := fs.String("output", "", "output format: "+strings.Join(outputFormats, ", "))
+	columns := fs.String("columns", "", "comma-separated columns for table output")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+
+	cfg, err := config.Load(o)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = cfg.Output
+	}
+	if *output != "" && !contains(outputFormats, *output) {
+		return fmt.Errorf("unknown output format %q (%s): %w", *output, strings.Join(outputFormats, ", "), errUsage)
+	}
+
+	r := &runner{cfg: cfg, stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	r.out = &printer{w: r.stdout, format: *output}
+	if *columns != "" {
+		r.out.columns = strings.Split(*columns, ",")
+	}
+	if r.store, err = credstore.Open(cfg.CredentialStore, cfg.CredentialsPath(), r.promptPassphrase); err != nil {
+		return err
+	}
+
+	opts, err := cfg.ClientOptions()
+	if err != nil {
+		return err
+	}
+	opts = append(opts, api.WithTokenObserver(r.persistRefreshedToken))
+	api.SetDefault(api.NewClient(opts...))
+	r.client = api.Default()
+
+	rest := fs.Args()
+	if len(rest) == 0 || rest[0] == "tui" {
+		// restore before tcell takes over the terminal
+		r.restoreToken()
+		return app.Run(cfg, r.store)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for _, c := range commands {
+		if c.name == rest[0] {
+			err := c.run(r, ctx, rest[1:])
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+	}
+	fmt.Fprint(os.Stderr, usage)
+	return fmt.Errorf("unknown command %q: %w", rest[0], errUsage)
+}
+
+// parseFlags parses fs allowing flags and positional arguments to be mixed,
+// e.g. "get db-password --master-stdin". It returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		rest := fs.Args()
+		// flag stops at and drops a "--" terminator; everything after it is positional
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// subcommand splits "secrets list ..." style arguments.
+func subcommand(group string, args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%s: missing subcommand (%s): %w", group, strings.Join(names, "|"), errUsage)
+	}
+	for _, n := range names {
+		if args[0] == n {
+			return n, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("%s: unknown subcommand %q (%s): %w", group, args[0], strings.Join(names, "|"), errUsage)
+}
+
+// requireToken fails early with a helpful message when no credentials are set.
+func (r *runner) requireToken() error {
+	r.restoreToken()
+	if !r.client.HasToken() {
+		if r.cfg.CredentialErr != nil {
+			return fmt.Errorf("%w: %v", errNotLoggedIn, r.cfg.CredentialErr)
+		}
+		return errNotLoggedIn
+	}
+	return nil
+}
+
+// restoreToken loads the saved token for the active profile when no
+// credentials came from flags, environment or the profile itself.
+func (r *runner) restoreToken() {
+	if r.restored || r.client.HasToken() {
+		return
+	}
+	r.restored = true
+	token, err := r.store.Get(r.cfg.Profile)
+	if err != nil {
+		if !errors.Is(err, credstore.ErrNotFound) {
+			fmt.Fprintf(r.stderr, "warning: restoring saved login from %s: %v\n", r.store.Name(), err)
+		}
+		return
+	}
+	r.client.SetToken(token)
+}
+
+// persistRefreshedToken saves a token the client refreshed on its own, unless
+// the credentials were configured explicitly. It runs in the background, so it
+// never prompts: a locked credentials file is simply left alone.
+func (r *runner) persistRefreshedToken(token string) {
+	if r.cfg.APIKey != "" {
+		return
+	}
+	if fs, ok := r.store.(*credstore.FileStore); ok && !fs.Unlocked() {
+		return
+	}
+	r.store.Set(r.cfg.Profile, token)
+}
+
+// usageErrorf reports bad positional arguments.
+func usageErrorf(format string, args ...interface{}) error {
+	return fmt.Errorf(format+": %w", append(args, errUsage)...)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
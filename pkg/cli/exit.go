@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"sm-cli/pkg/api"
+)
+
+// Process exit codes, stable for scripts.
+const (
+	ExitOK           = 0
+	ExitError        = 1 // anything not covered below
+	ExitUsage        = 2 // bad flags or arguments
+	ExitNotFound     = 3 // secret, key or endpoint does not exist
+	ExitUnauthorized = 4 // missing, expired or rejected credentials
+	ExitServer       = 5 // backend returned 5xx or could not be reached
+)
+
+// exitStatus is the exit code of a command started by "sm-cli run", passed
+// on unchanged.
+type exitStatus int
+
+func (e exitStatus) Error() string { return fmt.Sprintf("command exited with status %d", int(e)) }
+
+// Silent reports whether err only carries an exit code and should not be
+// printed, because the child command already reported its own failure.
+func Silent(err error) bool {
+	var status exitStatus
+	return errors.As(err, &status)
+}
+
+// ExitCode maps an error returned by Run to a process exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var apiErr *api.Error
+	var urlErr *url.Error
+	var status exitStatus
+	switch {
+	case errors.As(err, &status):
+		return int(status)
+	case errors.Is(err, errUsage):
+		return ExitUsage
+	case errors.Is(err, api.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, api.ErrUnauthorized), errors.Is(err, errNotLoggedIn):
+		return ExitUnauthorized
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden:
+		return ExitUnauthorized
+	case errors.As(err, &apiErr) && apiErr.StatusCode >= 500:
+		return ExitServer
+	case errors.As(err, &urlErr):
+		// the http client reports connection, TLS and timeout failures this way
+		return ExitServer
+	}
+	return ExitError
+}
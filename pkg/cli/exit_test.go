@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+
+	"sm-cli/pkg/api"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{usageErrorf("secrets get: expected a name"), ExitUsage},
+		{fmt.Errorf("secret %q: %w", "db", api.ErrNotFound), ExitNotFound},
+		{&api.Error{StatusCode: 404}, ExitNotFound},
+		{&api.Error{StatusCode: 401}, ExitUnauthorized},
+		{&api.Error{StatusCode: 403}, ExitUnauthorized},
+		{errNotLoggedIn, ExitUnauthorized},
+		{&api.Error{StatusCode: 500}, ExitServer},
+		{&api.Error{StatusCode: 503}, ExitServer},
+		{&api.Error{StatusCode: 409}, ExitError},
+		{&url.Error{Op: "Get", URL: "http://x", Err: errors.New("connection refused")}, ExitServer},
+		{&os.PathError{Op: "open", Path: "x", Err: os.ErrNotExist}, ExitError},
+		{exitStatus(7), 7},
+		{fmt.Errorf("run: %w", exitStatus(130)), 130},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSilent(t *testing.T) {
+	if !Silent(exitStatus(1)) || Silent(errors.New("x")) || Silent(nil) {
+		t.Error("Silent should only hold for a child's exit status")
+	}
+}
@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"sm-cli/pkg/api"
+)
+
+// Conflict policies for secrets that already exist.
+const (
+	conflictSkip      = "skip"
+	conflictOverwrite = "overwrite"
+	conflictRename    = "rename"
+)
+
+// importResult is one line of the import report. Values are never included.
+type importResult struct {
+	Source   string `json:"source"`
+	Name     string `json:"name"`
+	Category string `json:"category,omitempty"`
+	Action   string `json:"action"` // create, update, skip or fail
+	Result   string `json:"result"`
+
+	record *importRecord
+	id     string // existing secret to update
+}
+
+func (r *runner) importSecrets(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sm-cli import [flags] FILE")
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "auto", "input format: "+strings.Join(importFormats, ", "))
+	category := fs.String("category", r.cfg.DefaultCategory, "category for entries that do not set one")
+	columns := fs.String("map", "", "column/key mapping for CSV and record lists, e.g. name=KEY,value=SECRET")
+	nest := fs.String("nest", "name", `nested JSON/YAML keys: "name" joins them into the name, "category" uses the top-level key as category`)
+	onConflict := fs.String("on-conflict", conflictSkip, "when a secret exists: skip, overwrite or rename")
+	dryRun := fs.Bool("dry-run", false, "show what would happen without changing anything")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("import: expected exactly one file (\"-\" for stdin)")
+	}
+	if !contains([]string{conflictSkip, conflictOverwrite, conflictRename}, *onConflict) {
+		return usageErrorf("import: --on-conflict must be skip, overwrite or rename")
+	}
+	if *nest != "name" && *nest != "category" {
+		return usageErrorf("import: --nest must be name or category")
+	}
+	colMap, err := parseColumnMap(*columns)
+	if err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	if err := r.out.rejectEnv(); err != nil {
+		return err
+	}
+
+	path := pos[0]
+	var b []byte
+	if path == "-" {
+		b, err = io.ReadAll(r.stdin)
+		r.stdinDrained = true
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	if *format == "auto" {
+		*format = detectImportFormat(path)
+	}
+	records, err := parseImport(*format, b, colMap, *nest)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range records {
+		if records[i].Category == "" {
+			records[i].Category = *category
+		}
+	}
+
+	existing, err := r.client.AllSecrets(ctx)
+	if err != nil {
+		return err
+	}
+	plan := planImport(records, existing, *onConflict)
+
+	if !*dryRun && pending(plan) > 0 {
+		master, err := r.readMaster()
+		if err != nil {
+			return err
+		}
+		for i := range plan {
+			r.applyImport(ctx, &plan[i], master)
+		}
+	} else if *dryRun {
+		for i := range plan {
+			if plan[i].Action == "create" || plan[i].Action == "update" {
+				plan[i].Result = "dry run"
+			}
+		}
+	}
+
+	if err := r.out.print(plan, importTable(plan), nil); err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, p := range plan {
+		counts[p.Action]++
+	}
+	verb := "imported"
+	if *dryRun {
+		verb = "would import"
+	}
+	fmt.Fprintf(r.stderr, "%s %d: %d created, %d updated, %d skipped, %d failed\n",
+		verb, counts["create"]+counts["update"], counts["create"], counts["update"], counts["skip"], counts["fail"])
+	if counts["fail"] > 0 {
+		return fmt.Errorf("%d of %d entries failed to import", counts["fail"], len(plan))
+	}
+	return nil
+}
+
+// secretKey identifies a secret; names are only unique within a category.
+type secretKey struct{ category, name string }
+
+// secretIndex looks existing secrets, and those the plan will create, up by
+// category and name.
+type secretIndex struct {
+	byKey  map[secretKey]*api.Secret
+	byName map[string][]*api.Secret
+	taken  map[secretKey]bool
+}
+
+func newSecretIndex(existing []api.Secret) *secretIndex {
+	ix := &secretIndex{
+		byKey:  map[secretKey]*api.Secret{},
+		byName: map[string][]*api.Secret{},
+		taken:  map[secretKey]bool{},
+	}
+	for i := range existing {
+		s := &existing[i]
+		ix.byKey[secretKey{s.Category, s.Name}] = s
+		ix.byName[s.Name] = append(ix.byName[s.Name], s)
+		ix.taken[secretKey{s.Category, s.Name}] = true
+	}
+	return ix
+}
+
+// find returns the secrets a record refers to: the one in its category, or
+// every secret with that name when the record has no category.
+func (ix *secretIndex) find(category, name string) []*api.Secret {
+	if category != "" {
+		if s := ix.byKey[secretKey{category, name}]; s != nil {
+			return []*api.Secret{s}
+		}
+		return nil
+	}
+	return ix.byName[name]
+}
+
+// free reports whether name can be created in category without clashing.
+func (ix *secretIndex) free(category, name string) bool {
+	if ix.taken[secretKey{category, name}] {
+		return false
+	}
+	return category != "" || len(ix.byName[name]) == 0
+}
+
+// planImport decides what happens to each record.
+func planImport(records []importRecord, existing []api.Secret, policy string) []importResult {
+	ix := newSecretIndex(existing)
+	seen := map[secretKey]bool{}
+
+	plan := make([]importResult, 0, len(records))
+	for i := range records {
+		rec := &records[i]
+		res := importResult{Source: rec.Source, Name: rec.Name, Category: rec.Category, record: rec}
+		key := secretKey{rec.Category, rec.Name}
+		matches := ix.find(rec.Category, rec.Name)
+		switch {
+		case rec.Name == "":
+			res.Action, res.Result = "fail", "missing name"
+		case rec.Value == "":
+			res.Action, res.Result = "skip", "empty value"
+		case seen[key]:
+			res.Action, res.Result = "skip", "duplicate in input"
+		case len(matches) == 0:
+			res.Action = "create"
+		case len(matches) > 1:
+			res.Action, res.Result = "fail", "ambiguous: exists in categories "+matchCategories(matches)+"; set a category"
+		case policy == conflictOverwrite:
+			res.Action, res.id = "update", matches[0].ID
+			res.Category = matches[0].Category
+		case policy == conflictRename:
+			res.Action = "create"
+			res.Name = uniqueName(rec.Name, func(name string) bool { return ix.free(rec.Category, name) })
+			res.Result = "renamed from " + rec.Name
+		default:
+			res.Action, res.Result = "skip", "already exists"
+		}
+		if rec.Name != "" {
+			seen[key] = true
+		}
+		ix.taken[secretKey{res.Category, res.Name}] = true
+		plan = append(plan, res)
+	}
+	return plan
+}
+
+func matchCategories(matches []*api.Secret) string {
+	names := make([]string, len(matches))
+	for i, s := range matches {
+		names[i] = strconv.Quote(s.Category)
+	}
+	return strings.Join(names, ", ")
+}
+
+// uniqueName appends -2, -3, ... to name until free accepts it.
+func uniqueName(name string, free func(string) bool) string {
+	for n := 2; ; n++ {
+		candidate := name + "-" + strconv.Itoa(n)
+		if free(candidate) {
+			return candidate
+		}
+	}
+}
+
+func pending(plan []importResult) int {
+	n := 0
+	for _, p := range plan {
+		if p.Action == "create" || p.Action == "update" {
+			n++
+		}
+	}
+	return n
+}
+
+// applyImport creates or updates one secret and records the outcome.
+func (r *runner) applyImport(ctx context.Context, res *importResult, master string) {
+	rec := res.record
+	var err error
+	switch res.Action {
+	case "create":
+		_, err = r.client.CreateSecret(ctx, res.Name, rec.Value, rec.Category, rec.Description, master)
+	case "update":
+		// PUT replaces the whole secret, so start from the current values
+		// and only override what the input set
+		var cur *api.Secret
+		if cur, err = r.client.GetSecret(ctx, res.id, master); err != nil {
+			break
+		}
+		if rec.Description != "" {
+			cur.Description = rec.Description
+		}
+		_, err = r.client.UpdateSecret(ctx, res.id, cur.Name, rec.Value, cur.Category, cur.Description, master)
+	default:
+		return
+	}
+	if err != nil {
+		res.Action, res.Result = "fail", err.Error()
+		return
+	}
+	if res.Result == "" {
+		res.Result = "ok"
+	}
+}
+
+func importTable(plan []importResult) table {
+	t := table{columns: []string{"source", "action", "name", "category", "result"}}
+	for _, p := range plan {
+		t.rows = append(t.rows, map[string]string{
+			"source":   p.Source,
+			"action":   p.Action,
+			"name":     p.Name,
+			"category": p.Category,
+			"result":   p.Result,
+		})
+	}
+	return t
+}
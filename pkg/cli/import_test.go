@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"sm-cli/pkg/api"
+	"sm-cli/pkg/config"
+)
+
+func TestPlanImport(t *testing.T) {
+	existing := []api.Secret{
+		{ID: "s1", Name: "db", Category: "prod"},
+		{ID: "s2", Name: "db", Category: "staging"},
+		{ID: "s3", Name: "token", Category: "prod"},
+		{ID: "s4", Name: "token-2", Category: "prod"},
+		{ID: "s5", Name: "cache", Category: "prod"},
+	}
+	type step struct{ action, name, category, id, result string }
+	tests := []struct {
+		name    string
+		policy  string
+		records []importRecord
+		want    []step
+	}{
+		{
+			name:   "skip existing in the same category only",
+			policy: conflictSkip,
+			records: []importRecord{
+				{Name: "db", Value: "v", Category: "prod"},
+				{Name: "db", Value: "v", Category: "dev"},
+				{Name: "new", Value: "v", Category: "prod"},
+			},
+			want: []step{
+				{action: "skip", name: "db", category: "prod", result: "already exists"},
+				{action: "create", name: "db", category: "dev"},
+				{action: "create", name: "new", category: "prod"},
+			},
+		},
+		{
+			name:   "overwrite matches category and name",
+			policy: conflictOverwrite,
+			records: []importRecord{
+				{Name: "db", Value: "v", Category: "staging"},
+				{Name: "cache", Value: "v"},
+				{Name: "db", Value: "v"},
+			},
+			want: []step{
+				{action: "update", name: "db", category: "staging", id: "s2"},
+				{action: "update", name: "cache", category: "prod", id: "s5"},
+				{action: "fail", name: "db", result: `ambiguous: exists in categories "prod", "staging"; set a category`},
+			},
+		},
+		{
+			name:   "rename skips taken names",
+			policy: conflictRename,
+			records: []importRecord{
+				{Name: "token", Value: "v", Category: "prod"},
+				{Name: "token", Value: "w", Category: "prod"},
+				{Name: "token", Value: "v", Category: "dev"},
+			},
+			want: []step{
+				{action: "create", name: "token-3", category: "prod", result: "renamed from token"},
+				{action: "skip", name: "token", category: "prod", result: "duplicate in input"},
+				{action: "create", name: "token", category: "dev"},
+			},
+		},
+		{
+			name:   "missing name and empty value",
+			policy: conflictOverwrite,
+			records: []importRecord{
+				{Value: "v"},
+				{Name: "db", Category: "prod"},
+			},
+			want: []step{
+				{action: "fail", result: "missing name"},
+				{action: "skip", name: "db", category: "prod", result: "empty value"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planImport(tt.records, existing, tt.policy)
+			if len(plan) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d", len(plan), len(tt.want))
+			}
+			for i, p := range plan {
+				got := step{p.Action, p.Name, p.Category, p.id, p.Result}
+				if got != tt.want[i] {
+					t.Errorf("entry %d: got %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyImportUpdateKeepsFields(t *testing.T) {
+	var put map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(api.Secret{ID: "s1", Name: "db", Value: "old", Category: "prod", Description: "the db pw"})
+		case http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&put)
+			json.NewEncoder(w).Encode(api.Secret{ID: "s1"})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+	r := &runner{client: api.NewClient(api.WithBaseURL(srv.URL), api.WithToken("t"))}
+
+	existing := []api.Secret{{ID: "s1", Name: "db", Category: "prod", Description: "the db pw"}}
+	plan := planImport([]importRecord{{Name: "db", Value: "new"}}, existing, conflictOverwrite)
+	r.applyImport(context.Background(), &plan[0], "master")
+
+	if plan[0].Action != "update" || plan[0].Result != "ok" {
+		t.Fatalf("got %s/%s, want update/ok", plan[0].Action, plan[0].Result)
+	}
+	want := map[string]string{"name": "db", "value": "new", "category": "prod", "description": "the db pw"}
+	for k, v := range want {
+		if put[k] != v {
+			t.Errorf("PUT %s = %q, want %q (body %v)", k, put[k], v, put)
+		}
+	}
+}
+
+func TestImportRejectsEnvOutputFirst(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+	r := &runner{
+		cfg:    &config.Config{},
+		client: api.NewClient(api.WithBaseURL(srv.URL), api.WithToken("t")),
+		stdin:  strings.NewReader("DB=x\n"),
+		out:    &printer{w: io.Discard, format: formatEnv},
+	}
+	if err := r.importSecrets(context.Background(), []string{"-"}); !errors.Is(err, errUsage) {
+		t.Errorf("err = %v, want a usage error", err)
+	}
+	if requests != 0 {
+		t.Errorf("%d requests sent before rejecting --output env", requests)
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	taken := map[string]bool{"a-2": true, "a-3": true}
+	got := uniqueName("a", func(n string) bool { return !taken[n] })
+	if got != "a-4" {
+		t.Errorf("uniqueName = %q, want a-4", got)
+	}
+	if got := uniqueName("b", func(string) bool { return true }); got != "b-2" {
+		t.Errorf("uniqueName = %q, want b-2", got)
+	}
+}
@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Import file formats.
+const (
+	importDotenv = "dotenv"
+	importJSON   = "json"
+	importYAML   = "yaml"
+	importCSV    = "csv"
+)
+
+var importFormats = []string{"auto", importDotenv, importJSON, importYAML, importCSV}
+
+// importFields are the secret fields a column mapping can target.
+var importFields = []string{"name", "value", "category", "description"}
+
+// importRecord is one secret read from an import file.
+type importRecord struct {
+	Name        string
+	Value       string
+	Category    string
+	Description string
+	Source      string // position in the file, for messages
+}
+
+// detectImportFormat guesses the format from the file name; dotenv is the
+// fallback because .env files often have no extension.
+func detectImportFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return importJSON
+	case ".yaml", ".yml":
+		return importYAML
+	case ".csv":
+		return importCSV
+	}
+	return importDotenv
+}
+
+// parseColumnMap parses --map "name=KEY,value=SECRET" into field -> column.
+func parseColumnMap(spec string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, f := range importFields {
+		m[f] = f
+	}
+	if spec == "" {
+		return m, nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		field, col, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		if !ok || !contains(importFields, field) || strings.TrimSpace(col) == "" {
+			return nil, usageErrorf("import: bad --map entry %q, expected FIELD=COLUMN with FIELD one of %s", pair, strings.Join(importFields, ", "))
+		}
+		m[field] = strings.TrimSpace(col)
+	}
+	return m, nil
+}
+
+func parseImport(format string, b []byte, columns map[string]string, nest string) ([]importRecord, error) {
+	switch format {
+	case importDotenv:
+		return parseDotenv(b)
+	case importCSV:
+		return parseCSV(b, columns)
+	case importJSON:
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var data interface{}
+		if err := dec.Decode(&data); err != nil {
+			return nil, fmt.Errorf("json: %w", err)
+		}
+		return parseTree(data, columns, nest)
+	case importYAML:
+		var data interface{}
+		if err := yaml.Unmarshal(b, &data); err != nil {
+			return nil, fmt.Errorf("yaml: %w", err)
+		}
+		return parseTree(data, columns, nest)
+	}
+	return nil, usageErrorf("import: unknown format %q (%s)", format, strings.Join(importFormats, ", "))
+}
+
+// parseDotenv reads KEY=VALUE lines. It understands comments, "export",
+// single quotes (literal), and double quotes with \n, \t, \" and \\ escapes
+// that may span several lines.
+func parseDotenv(b []byte) ([]importRecord, error) {
+	var out []importRecord
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		start := lineNo
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, val, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+		val = strings.TrimLeft(val, " \t")
+
+		switch {
+		case strings.HasPrefix(val, `"`):
+			raw := val[1:]
+			for !closedQuote(raw) {
+				if !sc.Scan() {
+					return nil, fmt.Errorf("line %d: unterminated quoted value for %s", start, key)
+				}
+				lineNo++
+				raw += "\n" + sc.Text()
+			}
+			end := closingQuote(raw)
+			val = unescapeDotenv(raw[:end])
+		case strings.HasPrefix(val, "'"):
+			end := strings.Index(val[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value for %s", start, key)
+			}
+			val = val[1 : end+1]
+		default:
+			if i := strings.Index(val, " #"); i >= 0 {
+				val = val[:i]
+			}
+			val = strings.TrimSpace(val)
+		}
+		out = append(out, importRecord{Name: key, Value: val, Source: fmt.Sprintf("line %d", start)})
+	}
+	return out, sc.Err()
+}
+
+// closingQuote returns the index of the first unescaped '"' in s, or -1.
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func closedQuote(s string) bool { return closingQuote(s) >= 0 }
+
+func unescapeDotenv(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return r.Replace(s)
+}
+
+// parseCSV reads a CSV file with a header row; columns picks the header for
+// each secret field.
+func parseCSV(b []byte, columns map[string]string) ([]importRecord, error) {
+	cr := csv.NewReader(bytes.NewReader(b))
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("csv: %w", err)
+	}
+	index := map[string]int{}
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	pos := map[string]int{}
+	for _, f := range importFields {
+		i, ok := index[strings.ToLower(columns[f])]
+		if !ok {
+			if f == "name" || f == "value" {
+				return nil, usageErrorf("import: csv has no %q column for %s (use --map %s=COLUMN)", columns[f], f, f)
+			}
+			i = -1
+		}
+		pos[f] = i
+	}
+
+	var out []importRecord
+	for row := 2; ; row++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv: %w", err)
+		}
+		get := func(f string) string {
+			if i := pos[f]; i >= 0 && i < len(rec) {
+				return rec[i]
+			}
+			return ""
+		}
+		out = append(out, importRecord{
+			Name:        strings.TrimSpace(get("name")),
+			Value:       get("value"),
+			Category:    strings.TrimSpace(get("category")),
+			Description: get("description"),
+			Source:      fmt.Sprintf("row %d", row),
+		})
+	}
+}
+
+// parseTree turns decoded JSON or YAML into records. A list of objects (or
+// an object holding one under "secrets") is read as records through the
+// column mapping. Any other object is
+// flattened: nested keys are joined with "/" into the name, or with
+// nest=category the top-level key becomes the category.
+func parseTree(data interface{}, columns map[string]string, nest string) ([]importRecord, error) {
+	if m, ok := data.(map[string]interface{}); ok {
+		if list, ok := m["secrets"].([]interface{}); ok {
+			data = list
+		}
+	}
+	switch v := data.(type) {
+	case []interface{}:
+		var out []importRecord
+		for i, item := range v {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("item %d: expected an object", i+1)
+			}
+			field := func(f string) string {
+				if s, ok := obj[columns[f]]; ok && s != nil {
+					return scalarString(s)
+				}
+				return ""
+			}
+			out = append(out, importRecord{
+				Name:        field("name"),
+				Value:       field("value"),
+				Category:    field("category"),
+				Description: field("description"),
+				Source:      fmt.Sprintf("item %d", i+1),
+			})
+		}
+		return out, nil
+	case map[string]interface{}:
+		var out []importRecord
+		for _, k := range sortedKeys(v) {
+			if nest == "category" {
+				if child, ok := v[k].(map[string]interface{}); ok {
+					recs, err := flatten(child, "")
+					if err != nil {
+						return nil, err
+					}
+					for i := range recs {
+						recs[i].Category = k
+						recs[i].Source = "key " + k + "/" + recs[i].Name
+					}
+					out = append(out, recs...)
+					continue
+				}
+			}
+			recs, err := flatten(map[string]interface{}{k: v[k]}, "")
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, recs...)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("expected an object or a list of objects at the top level")
+}
+
+// flatten walks nested objects, joining keys with "/".
+func flatten(m map[string]interface{}, prefix string) ([]importRecord, error) {
+	var out []importRecord
+	for _, k := range sortedKeys(m) {
+		name := k
+		if prefix != "" {
+			name = prefix + "/" + k
+		}
+		switch v := m[k].(type) {
+		case map[string]interface{}:
+			recs, err := flatten(v, name)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, recs...)
+		case []interface{}:
+			return nil, fmt.Errorf("%s: lists are not supported outside a list of records", name)
+		default:
+			out = append(out, importRecord{Name: name, Value: scalarString(v), Source: "key " + name})
+		}
+	}
+	return out, nil
+}
+
+// scalarString formats a JSON/YAML scalar the way it was written.
+func scalarString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case json.Number:
+		return s.String()
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
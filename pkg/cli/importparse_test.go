@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	in := `# comment
+export API_KEY=abc123
+PLAIN = spaced value  # trailing comment
+HASH=a#b
+SINGLE='keep \n and "quotes"'
+DOUBLE="tab\there \"q\" back\\slash"
+MULTI="-----BEGIN-----
+line two
+-----END-----"
+EMPTY=
+`
+	got, err := parseDotenv([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []importRecord{
+		{Name: "API_KEY", Value: "abc123", Source: "line 2"},
+		{Name: "PLAIN", Value: "spaced value", Source: "line 3"},
+		{Name: "HASH", Value: "a#b", Source: "line 4"},
+		{Name: "SINGLE", Value: `keep \n and "quotes"`, Source: "line 5"},
+		{Name: "DOUBLE", Value: "tab\there \"q\" back\\slash", Source: "line 6"},
+		{Name: "MULTI", Value: "-----BEGIN-----\nline two\n-----END-----", Source: "line 7"},
+		{Name: "EMPTY", Value: "", Source: "line 10"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDotenv:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	for _, in := range []string{
+		"NOEQUALS\n",
+		"=value\n",
+		"OPEN=\"never closed\nstill open\n",
+		"OPEN='never closed\n",
+	} {
+		if _, err := parseDotenv([]byte(in)); err == nil {
+			t.Errorf("parseDotenv(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	in := "Name,Value,Category,Notes\n" +
+		"db, hunter2 ,prod,main db\n" +
+		"\"cert\",\"a,b\nc\",,\n" +
+		"short\n"
+	cols, err := parseColumnMap("description=notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseCSV([]byte(in), cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []importRecord{
+		{Name: "db", Value: " hunter2 ", Category: "prod", Description: "main db", Source: "row 2"},
+		{Name: "cert", Value: "a,b\nc", Source: "row 3"},
+		{Name: "short", Source: "row 4"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCSV:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseCSVMissingColumn(t *testing.T) {
+	cols, _ := parseColumnMap("")
+	_, err := parseCSV([]byte("key,secret\na,b\n"), cols)
+	if !errors.Is(err, errUsage) {
+		t.Errorf("parseCSV without a value column: err = %v, want a usage error", err)
+	}
+}
+
+func TestParseColumnMap(t *testing.T) {
+	m, err := parseColumnMap("name=KEY, value = SECRET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"name": "KEY", "value": "SECRET", "category": "category", "description": "description"}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("parseColumnMap = %v, want %v", m, want)
+	}
+	for _, spec := range []string{"name", "bogus=x", "value="} {
+		if _, err := parseColumnMap(spec); !errors.Is(err, errUsage) {
+			t.Errorf("parseColumnMap(%q): err = %v, want a usage error", spec, err)
+		}
+	}
+}
+
+func TestParseImportTree(t *testing.T) {
+	cols, _ := parseColumnMap("")
+	tests := []struct {
+		name   string
+		format string
+		in     string
+		nest   string
+		want   []importRecord
+	}{
+		{
+			name:   "nested json joins keys",
+			format: importJSON,
+			in:     `{"db": {"user": "app", "port": 5432}, "token": "t"}`,
+			nest:   "name",
+			want: []importRecord{
+				{Name: "db/port", Value: "5432", Source: "key db/port"},
+				{Name: "db/user", Value: "app", Source: "key db/user"},
+				{Name: "token", Value: "t", Source: "key token"},
+			},
+		},
+		{
+			name:   "nested yaml by category",
+			format: importYAML,
+			in:     "prod:\n  db: x\n  cache:\n    url: y\ntop: z\n",
+			nest:   "category",
+			want: []importRecord{
+				{Name: "cache/url", Value: "y", Category: "prod", Source: "key prod/cache/url"},
+				{Name: "db", Value: "x", Category: "prod", Source: "key prod/db"},
+				{Name: "top", Value: "z", Source: "key top"},
+			},
+		},
+		{
+			name:   "list of records",
+			format: importJSON,
+			in:     `{"secrets": [{"name": "a", "value": "1", "category": "c", "description": "d"}, {"name": "b", "value": null}]}`,
+			nest:   "name",
+			want: []importRecord{
+				{Name: "a", Value: "1", Category: "c", Description: "d", Source: "item 1"},
+				{Name: "b", Source: "item 2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseImport(tt.format, []byte(tt.in), cols, tt.nest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseImport:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportTreeErrors(t *testing.T) {
+	cols, _ := parseColumnMap("")
+	for _, in := range []string{`"scalar"`, `[1, 2]`, `{"a": [1]}`, `{`} {
+		if _, err := parseImport(importJSON, []byte(in), cols, "name"); err == nil {
+			t.Errorf("parseImport(%s) succeeded, want error", in)
+		}
+	}
+}
+
+func TestDetectImportFormat(t *testing.T) {
+	for path, want := range map[string]string{
+		"secrets.JSON": importJSON,
+		"a.yml":        importYAML,
+		"a.yaml":       importYAML,
+		"x.csv":        importCSV,
+		".env":         importDotenv,
+		"prod.env":     importDotenv,
+		"-":            importDotenv,
+	} {
+		if got := detectImportFormat(path); got != want {
+			t.Errorf("detectImportFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
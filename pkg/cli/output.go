@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"sm-cli/pkg/api"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatEnv   = "env"
+)
+
+var outputFormats = []string{formatTable, formatJSON, formatYAML, formatEnv}
+
+// printer renders command results in the format chosen with --output.
+type printer struct {
+	w       io.Writer
+	format  string   // empty means the command's human-readable default
+	columns []string // --columns for table output
+}
+
+// table is the tabular view of a result: the default column order plus one
+// map per row keyed by column name.
+type table struct {
+	columns []string
+	rows    []map[string]string
+}
+
+// envVar is a single KEY=VALUE line of env output.
+type envVar struct {
+	name, value string
+}
+
+// structured reports whether the user asked for machine-readable output.
+func (p *printer) structured() bool {
+	return p.format != "" && p.format != formatTable
+}
+
+var errNoEnv = fmt.Errorf("--output env is not supported by this command: %w", errUsage)
+
+// rejectEnv fails for --output env in commands that have no env
+// representation. Commands that change something call it before sending the
+// request, so the change is not made only for printing to fail.
+func (p *printer) rejectEnv() error {
+	if p.format == formatEnv {
+		return errNoEnv
+	}
+	return nil
+}
+
+// print writes data as json/yaml, t as a table, or env as KEY=VALUE lines.
+// A nil env means the command has no env representation.
+func (p *printer) print(data interface{}, t table, env []envVar) error {
+	switch p.format {
+	case formatJSON:
+		b, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", b)
+		return err
+	case formatYAML:
+		// go through JSON so field names match the API's json tags
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = p.w.Write(out)
+		return err
+	case formatEnv:
+		if env == nil {
+			return errNoEnv
+		}
+		for _, v := range env {
+			if _, err := fmt.Fprintf(p.w, "%s=%s\n", v.name, shellQuote(v.value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return p.printTable(t)
+}
+
+func (p *printer) printTable(t table) error {
+	cols := t.columns
+	if len(p.columns) > 0 {
+		cols = p.columns
+		if len(t.rows) > 0 {
+			for _, c := range cols {
+				if _, ok := t.rows[0][c]; !ok {
+					return fmt.Errorf("unknown column %q: %w", c, errUsage)
+				}
+			}
+		}
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = strings.ToUpper(strings.ReplaceAll(c, "_", " "))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range t.rows {
+		vals := make([]string, len(cols))
+		for i, c := range cols {
+			vals[i] = row[c]
+		}
+		fmt.Fprintln(tw, strings.Join(vals, "\t"))
+	}
+	return tw.Flush()
+}
+
+func secretTable(secrets []api.Secret) table {
+	t := table{columns: []string{"id", "name", "category", "updated"}}
+	for _, s := range secrets {
+		t.rows = append(t.rows, map[string]string{
+			"id":          s.ID,
+			"name":        s.Name,
+			"category":    s.Category,
+			"description": s.Description,
+			"value":       s.Value,
+			"created":     formatTime(s.CreatedAt),
+			"updated":     formatTime(s.UpdatedAt),
+		})
+	}
+	return t
+}
+
+func secretEnv(secrets []api.Secret) []envVar {
+	env := make([]envVar, 0, len(secrets))
+	for _, s := range secrets {
+		env = append(env, envVar{envName(s.Name), s.Value})
+	}
+	return env
+}
+
+func apiKeyTable(keys []api.APIKey) table {
+	t := table{columns: []string{"id", "name", "prefix", "status", "created", "last_used"}}
+	for _, k := range keys {
+		t.rows = append(t.rows, map[string]string{
+			"id":        k.ID,
+			"name":      k.Name,
+			"prefix":    k.Prefix,
+			"status":    k.Status,
+			"created":   formatTime(k.CreatedAt),
+			"last_used": formatTimePtr(k.LastUsedAt),
+			"expires":   formatTimePtr(k.ExpiresAt),
+		})
+	}
+	return t
+}
+
+// envName turns a secret name like "db-password" into DB_PASSWORD.
+func envName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if i == 0 && unicode.IsDigit(r) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+// shellQuote single-quotes v unless it only contains shell-safe characters,
+// so env output can be sourced by sh.
+func shellQuote(v string) string {
+	if shellSafe.MatchString(v) {
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
@@ -0,0 +1,33 @@
+package cli
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	for in, want := range map[string]string{
+		"plain":           "plain",
+		"a/b:c@d.e-f_g+1": "a/b:c@d.e-f_g+1",
+		"":                "",
+		"two words":       "'two words'",
+		"it's":            `'it'\''s'`,
+		"$HOME":           "'$HOME'",
+		"a\nb":            "'a\nb'",
+		"`cmd`":           "'`cmd`'",
+	} {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for in, want := range map[string]string{
+		"db-password": "DB_PASSWORD",
+		"api.key":     "API_KEY",
+		"2fa":         "_2FA",
+		"café":        "CAF_",
+	} {
+		if got := envName(in); got != want {
+			t.Errorf("envName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
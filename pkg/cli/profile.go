@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+
+	"sm-cli/pkg/api"
+	"sm-cli/pkg/config"
+)
+
+func (r *runner) profile(ctx context.Context, args []string) error {
+	sub, args, err := subcommand("profile", args, "list", "use", "add", "remove")
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		return r.profileList(args)
+	case "use":
+		return r.profileUse(args)
+	case "add":
+		return r.profileAdd(args)
+	default:
+		return r.profileRemove(args)
+	}
+}
+
+func (r *runner) profileList(args []string) error {
+	fs := flag.NewFlagSet("profile list", flag.ContinueOnError)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	f, err := config.ReadFile(r.cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	names := []string{config.DefaultProfile}
+	for name := range f.Profiles {
+		if name != config.DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+
+	t := table{columns: []string{"active", "name", "backend", "default_category", "credential"}}
+	for _, name := range names {
+		p, ok := f.Profiles[name]
+		if !ok {
+			p = &f.Profile
+		}
+		active := ""
+		if name == r.cfg.Profile {
+			active = "*"
+		}
+		backend := p.BackendURL
+		if backend == "" {
+			backend = api.DefaultBaseURL
+		}
+		cred := p.Credential
+		if cred == "" && p.APIKey != "" {
+			cred = "api_key"
+		}
+		t.rows = append(t.rows, map[string]string{
+			"active":           active,
+			"name":             name,
+			"backend":          backend,
+			"default_category": p.DefaultCategory,
+			"credential":       cred,
+		})
+	}
+	return r.out.print(t.rows, t, nil)
+}
+
+func (r *runner) profileUse(args []string) error {
+	fs := flag.NewFlagSet("profile use", flag.ContinueOnError)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("profile use: expected exactly one profile name")
+	}
+	f, err := config.ReadFile(r.cfg.Path)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Profiles[pos[0]]; !ok && pos[0] != config.DefaultProfile {
+		return fmt.Errorf("unknown profile %q", pos[0])
+	}
+	f.Active = pos[0]
+	if pos[0] == config.DefaultProfile {
+		f.Active = ""
+	}
+	if err := config.WriteFile(r.cfg.Path, f); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.stdout, "Using profile %s\n", pos[0])
+	return nil
+}
+
+func (r *runner) profileAdd(args []string) error {
+	fs := flag.NewFlagSet("profile add", flag.ContinueOnError)
+	var p config.Profile
+	fs.StringVar(&p.BackendURL, "backend", "", "backend URL (required)")
+	fs.StringVar(&p.Credential, "credential", "", "credential reference: env:NAME or file:PATH")
+	fs.StringVar(&p.DefaultCategory, "category", "", "default category for new secrets")
+	fs.StringVar(&p.TLS.CAFile, "ca-file", "", "PEM bundle to trust for this backend")
+	fs.BoolVar(&p.TLS.InsecureSkipVerify, "insecure", false, "skip TLS certificate verification")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("profile add: expected exactly one profile name")
+	}
+	if p.BackendURL == "" {
+		return usageErrorf("profile add: --backend is required")
+	}
+	if err := config.ValidateBackendURL(p.BackendURL); err != nil {
+		return usageErrorf("profile add: %v", err)
+	}
+	if p.Credential != "" {
+		if _, err := config.ResolveCredential(p.Credential); err != nil {
+			fmt.Fprintf(r.stderr, "warning: %v\n", err)
+		}
+	}
+
+	f, err := config.ReadFile(r.cfg.Path)
+	if err != nil {
+		return err
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]*config.Profile{}
+	}
+	_, existed := f.Profiles[pos[0]]
+	f.Profiles[pos[0]] = &p
+	if err := config.WriteFile(r.cfg.Path, f); err != nil {
+		return err
+	}
+	verb := "Added"
+	if existed {
+		verb = "Replaced"
+	}
+	fmt.Fprintf(r.stdout, "%s profile %s\n", verb, pos[0])
+	return nil
+}
+
+func (r *runner) profileRemove(args []string) error {
+	fs := flag.NewFlagSet("profile remove", flag.ContinueOnError)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("profile remove: expected exactly one profile name")
+	}
+	f, err := config.ReadFile(r.cfg.Path)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Profiles[pos[0]]; !ok {
+		return fmt.Errorf("unknown profile %q", pos[0])
+	}
+	// drop the saved login too, or re-adding the name would silently reuse it
+	if err := r.store.Delete(pos[0]); err != nil {
+		return fmt.Errorf("removing saved login: %w", err)
+	}
+	delete(f.Profiles, pos[0])
+	if f.Active == pos[0] {
+		f.Active = ""
+	}
+	if err := config.WriteFile(r.cfg.Path, f); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.stdout, "Removed profile %s\n", pos[0])
+	return nil
+}
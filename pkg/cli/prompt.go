@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sm-cli/pkg/credstore"
+
+	"golang.org/x/term"
+)
+
+// readSecret reads a value without echo when stdin is a terminal, otherwise
+// it takes the next line from stdin so values can be piped in.
+func (r *runner) readSecret(prompt string) (string, error) {
+	if r.stdinIsTerminal() {
+		f := r.stdin.(*os.File)
+		fmt.Fprint(r.stderr, prompt+": ")
+		b, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(r.stderr)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	return r.readLine()
+}
+
+// readMaster asks for the master password. It fails clearly when the command
+// already consumed a piped stdin, e.g. for a template or import file.
+func (r *runner) readMaster() (string, error) {
+	if r.stdinDrained && !r.stdinIsTerminal() {
+		return "", fmt.Errorf("cannot read the master password: stdin was used for input: %w", errUsage)
+	}
+	return r.readSecret("Master password")
+}
+
+// stdinIsTerminal reports whether prompts can be answered interactively.
+func (r *runner) stdinIsTerminal() bool {
+	f, ok := r.stdin.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// promptPassphrase asks for the credentials file passphrase on the terminal,
+// twice when the file is about to be created.
+func (r *runner) promptPassphrase() (string, error) {
+	if !r.stdinIsTerminal() {
+		return "", fmt.Errorf("no terminal to ask for the credentials passphrase; set %s", credstore.EnvPassphrase)
+	}
+	pass, err := r.readSecret("Credentials passphrase")
+	if err != nil {
+		return "", err
+	}
+	if fs, ok := r.store.(*credstore.FileStore); ok && !fs.Exists() {
+		again, err := r.readSecret("Repeat passphrase")
+		if err != nil {
+			return "", err
+		}
+		if again != pass {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return pass, nil
+}
+
+// readLine reads one line from stdin, without the trailing newline.
+func (r *runner) readLine() (string, error) {
+	if r.lines == nil {
+		r.lines = bufio.NewReader(r.stdin)
+	}
+	line, err := r.lines.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("reading stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readValue loads a secret value from path ("-" for all of stdin), or prompts
+// for it when path is empty.
+func (r *runner) readValue(path string) (string, error) {
+	switch path {
+	case "":
+		return r.readSecret("Value")
+	case "-":
+		// readSecret may already have buffered part of a piped stdin, so
+		// drain through the same reader rather than r.stdin.
+		var in io.Reader = r.stdin
+		if r.lines != nil {
+			in = r.lines
+		}
+		b, err := io.ReadAll(in)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(b), "\n"), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadValueAfterPipedMaster(t *testing.T) {
+	r := &runner{stdin: strings.NewReader("master\nline one\nline two\n")}
+	master, err := r.readSecret("Master password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := r.readValue("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master != "master" || value != "line one\nline two" {
+		t.Errorf("got master %q, value %q", master, value)
+	}
+}
+
+func TestReadValueAllOfStdin(t *testing.T) {
+	r := &runner{stdin: strings.NewReader("only value\n")}
+	value, err := r.readValue("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "only value" {
+		t.Errorf("value = %q, want %q", value, "only value")
+	}
+}
+
+func TestReadMasterAfterDrainedStdin(t *testing.T) {
+	r := &runner{stdin: strings.NewReader("master\n"), stdinDrained: true}
+	if _, err := r.readMaster(); err == nil {
+		t.Error("readMaster succeeded after stdin was drained")
+	}
+}
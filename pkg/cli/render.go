@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"sm-cli/pkg/api"
+)
+
+// render executes a text/template with secret lookups and prints the result.
+// Output is only written once the whole template rendered successfully.
+func (r *runner) render(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sm-cli render [--strict] [--check] [--out FILE] TEMPLATE")
+		fs.PrintDefaults()
+	}
+	strict := fs.Bool("strict", false, "fail on references to unknown secrets or categories")
+	check := fs.Bool("check", false, "only verify that every reference exists; nothing is decrypted or printed")
+	out := fs.String("out", "", "write to FILE (mode 0600) instead of stdout")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("render: expected exactly one template file (\"-\" for stdin)")
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+
+	name, text, err := r.readTemplate(pos[0])
+	if err != nil {
+		return err
+	}
+	all, err := r.client.AllSecrets(ctx)
+	if err != nil {
+		return err
+	}
+	rd := &renderer{r: r, ctx: ctx, all: all, strict: *strict && !*check, check: *check, values: map[string]string{}}
+	tmpl := template.New(name).Funcs(rd.funcs())
+	if rd.strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	if tmpl, err = tmpl.Parse(text); err != nil {
+		return fmt.Errorf("%w: %w", err, errUsage)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return err
+	}
+
+	if rd.check {
+		for _, m := range rd.missing {
+			fmt.Fprintf(r.stderr, "%s: unknown %s\n", name, m)
+		}
+		if len(rd.missing) > 0 {
+			return fmt.Errorf("%d unknown reference(s): %w", len(rd.missing), api.ErrNotFound)
+		}
+		fmt.Fprintf(r.stderr, "%s: %d reference(s), all found\n", name, rd.refs)
+		return nil
+	}
+	for _, m := range rd.missing {
+		fmt.Fprintf(r.stderr, "warning: %s: unknown %s rendered as empty\n", name, m)
+	}
+	if *out != "" {
+		return writePrivate(*out, buf.Bytes())
+	}
+	_, err = r.stdout.Write(buf.Bytes())
+	return err
+}
+
+// readTemplate loads the template from path, or from stdin for "-".
+func (r *runner) readTemplate(path string) (name, text string, err error) {
+	if path == "-" {
+		b, err := io.ReadAll(r.stdin)
+		r.stdinDrained = true
+		return "stdin", string(b), err
+	}
+	b, err := os.ReadFile(path)
+	return filepath.Base(path), string(b), err
+}
+
+// renderer provides the template functions and remembers what they touched.
+type renderer struct {
+	r      *runner
+	ctx    context.Context
+	all    []api.Secret
+	strict bool
+	check  bool
+
+	master  *string           // prompted for on first use
+	values  map[string]string // decrypted values by secret id
+	refs    int
+	missing []string
+}
+
+func (rd *renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"secret":            rd.secret,
+		"secretsByCategory": rd.secretsByCategory,
+	}
+}
+
+// secret returns the value of the secret named ref (or its id, or
+// CATEGORY/NAME).
+func (rd *renderer) secret(ref string) (string, error) {
+	rd.refs++
+	s, err := findSecret(rd.all, ref)
+	if err != nil {
+		return "", rd.unknown(fmt.Sprintf("secret %q", ref), err)
+	}
+	return rd.value(s)
+}
+
+// secretsByCategory returns the values of every secret in category, keyed
+// by secret name.
+func (rd *renderer) secretsByCategory(category string) (map[string]string, error) {
+	rd.refs++
+	out := map[string]string{}
+	for i := range rd.all {
+		if rd.all[i].Category != category {
+			continue
+		}
+		v, err := rd.value(&rd.all[i])
+		if err != nil {
+			return nil, err
+		}
+		out[rd.all[i].Name] = v
+	}
+	if len(out) == 0 {
+		return out, rd.unknown(fmt.Sprintf("category %q", category), fmt.Errorf("category %q: %w", category, api.ErrNotFound))
+	}
+	return out, nil
+}
+
+// unknown records a missing reference; only strict mode turns it into an error.
+func (rd *renderer) unknown(what string, err error) error {
+	for _, m := range rd.missing {
+		if m == what {
+			return nil
+		}
+	}
+	rd.missing = append(rd.missing, what)
+	if rd.strict {
+		return err
+	}
+	return nil
+}
+
+// value decrypts s, asking for the master password the first time.
+func (rd *renderer) value(s *api.Secret) (string, error) {
+	if rd.check {
+		return "", nil
+	}
+	if v, ok := rd.values[s.ID]; ok {
+		return v, nil
+	}
+	if rd.master == nil {
+		m, err := rd.r.readMaster()
+		if err != nil {
+			return "", err
+		}
+		rd.master = &m
+	}
+	full, err := rd.r.client.GetSecret(rd.ctx, s.ID, *rd.master)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %w", s.Name, err)
+	}
+	rd.values[s.ID] = full.Value
+	return full.Value, nil
+}
+
+// writePrivate atomically replaces path with b, readable only by the owner.
+func writePrivate(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
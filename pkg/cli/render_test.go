@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"sm-cli/pkg/api"
+)
+
+// fakeVault serves the secret list and single secrets with their values, and
+// counts how many values were fetched.
+type fakeVault struct {
+	secrets []api.Secret
+	gets    int32
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v1/secrets" {
+		list := make([]api.Secret, len(v.secrets))
+		for i, s := range v.secrets {
+			s.Value = ""
+			list[i] = s
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"secrets":    list,
+			"pagination": map[string]int{"page": 1, "limit": 100, "total": len(list)},
+		})
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/secrets/")
+	for _, s := range v.secrets {
+		if s.ID == id {
+			atomic.AddInt32(&v.gets, 1)
+			json.NewEncoder(w).Encode(s)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// newTestRunner returns a runner talking to v, with stdin holding the master
+// password.
+func newTestRunner(t *testing.T, v *fakeVault) (*runner, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	srv := httptest.NewServer(v)
+	t.Cleanup(srv.Close)
+	var stdout, stderr bytes.Buffer
+	return &runner{
+		client: api.NewClient(api.WithBaseURL(srv.URL), api.WithToken("t")),
+		stdin:  strings.NewReader("master\n"),
+		stdout: &stdout,
+		stderr: &stderr,
+	}, &stdout, &stderr
+}
+
+func writeTemplate(t *testing.T, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.conf.tmpl")
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+var renderVault = []api.Secret{
+	{ID: "s1", Name: "db", Category: "prod", Value: "hunter2"},
+	{ID: "s2", Name: "smtp", Category: "mail", Value: "mailpw"},
+}
+
+func TestRender(t *testing.T) {
+	v := &fakeVault{secrets: renderVault}
+	r, stdout, stderr := newTestRunner(t, v)
+	tmpl := writeTemplate(t, `db={{ secret "db" }} {{ range $k, $v := secretsByCategory "mail" }}{{ $k }}={{ $v }}{{ end }} x={{ secret "missing" }}`)
+	if err := r.render(context.Background(), []string{tmpl}); err != nil {
+		t.Fatal(err)
+	}
+	if got := stdout.String(); got != "db=hunter2 smtp=mailpw x=" {
+		t.Errorf("output %q", got)
+	}
+	if !strings.Contains(stderr.String(), `unknown secret "missing"`) {
+		t.Errorf("no warning for the unknown secret: %q", stderr.String())
+	}
+}
+
+func TestRenderStrict(t *testing.T) {
+	v := &fakeVault{secrets: renderVault}
+	r, stdout, _ := newTestRunner(t, v)
+	tmpl := writeTemplate(t, `db={{ secret "db" }} x={{ secret "missing" }}`)
+	err := r.render(context.Background(), []string{"--strict", tmpl})
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("partial output written: %q", stdout.String())
+	}
+}
+
+func TestRenderCheck(t *testing.T) {
+	v := &fakeVault{secrets: renderVault}
+	r, stdout, stderr := newTestRunner(t, v)
+	tmpl := writeTemplate(t, `{{ secret "db" }}{{ secret "prod/db" }}{{ secretsByCategory "mail" }}`)
+	if err := r.render(context.Background(), []string{"--check", tmpl}); err != nil {
+		t.Fatal(err)
+	}
+	if v.gets != 0 || stdout.Len() != 0 {
+		t.Errorf("--check decrypted %d secrets and printed %q", v.gets, stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "3 reference(s), all found") {
+		t.Errorf("stderr %q", stderr.String())
+	}
+
+	r, _, stderr = newTestRunner(t, v)
+	tmpl = writeTemplate(t, `{{ secret "nope" }}{{ secretsByCategory "none" }}`)
+	err := r.render(context.Background(), []string{"--check", tmpl})
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if !strings.Contains(stderr.String(), `unknown secret "nope"`) || !strings.Contains(stderr.String(), `unknown category "none"`) {
+		t.Errorf("stderr %q", stderr.String())
+	}
+}
+
+func TestRenderOutFile(t *testing.T) {
+	v := &fakeVault{secrets: renderVault}
+	r, stdout, _ := newTestRunner(t, v)
+	out := filepath.Join(t.TempDir(), "app.conf")
+	if err := r.render(context.Background(), []string{"--out", out, writeTemplate(t, `{{ secret "db" }}`)}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil || string(b) != "hunter2" || stdout.Len() != 0 {
+		t.Fatalf("out file %q, %v; stdout %q", b, err, stdout.String())
+	}
+	if fi, _ := os.Stat(out); fi.Mode().Perm() != 0o600 {
+		t.Errorf("mode %v, want 0600", fi.Mode().Perm())
+	}
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+
+	"sm-cli/pkg/api"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// binding maps one environment variable to a secret.
+type binding struct {
+	env    string
+	secret *api.Secret
+}
+
+// run starts a command with secrets exported into its environment. Values
+// are only ever handed to the child, never printed.
+func (r *runner) run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sm-cli run [--secret [ENV=]NAME]... [--category CATEGORY]... [--] COMMAND [ARGS]")
+		fs.PrintDefaults()
+	}
+	var secrets, categories stringList
+	fs.Var(&secrets, "secret", "export a secret as ENV=NAME, NAME or ENV=CATEGORY/NAME (repeatable)")
+	fs.Var(&categories, "category", "export every secret in CATEGORY under its env-style name (repeatable)")
+	// flags end at the command, so its own flags are left alone
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	argv := fs.Args()
+	if len(argv) == 0 {
+		return usageErrorf("run: missing command")
+	}
+	if len(secrets) == 0 && len(categories) == 0 {
+		return usageErrorf("run: nothing to export, pass --secret or --category")
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+
+	all, err := r.client.AllSecrets(ctx)
+	if err != nil {
+		return err
+	}
+	bindings, err := planBindings(all, secrets, categories)
+	if err != nil {
+		return err
+	}
+
+	master, err := r.readSecret("Master password")
+	if err != nil {
+		return err
+	}
+	env := os.Environ()
+	for _, b := range bindings {
+		full, err := r.client.GetSecret(ctx, b.secret.ID, master)
+		if err != nil {
+			return fmt.Errorf("secret %q: %w", b.secret.Name, err)
+		}
+		env = append(env, b.env+"="+full.Value)
+	}
+	return r.exec(argv, env)
+}
+
+// planBindings resolves --secret and --category into environment variables.
+// Explicit --secret entries win over names derived from a category.
+func planBindings(all []api.Secret, secrets, categories []string) ([]binding, error) {
+	var out []binding
+	index := map[string]int{}
+	add := func(b binding, explicit bool) error {
+		if i, ok := index[b.env]; ok {
+			if explicit && out[i].secret.ID != b.secret.ID {
+				return usageErrorf("run: %s is bound to both %q and %q", b.env, out[i].secret.Name, b.secret.Name)
+			}
+			return nil
+		}
+		index[b.env] = len(out)
+		out = append(out, b)
+		return nil
+	}
+
+	for _, spec := range secrets {
+		env, ref, ok := strings.Cut(spec, "=")
+		if !ok {
+			ref, env = spec, ""
+		}
+		s, err := findSecret(all, ref)
+		if err != nil {
+			return nil, err
+		}
+		if env == "" {
+			env = envName(s.Name)
+		}
+		if !envNamePattern.MatchString(env) {
+			return nil, usageErrorf("run: %q is not a valid environment variable name", env)
+		}
+		if err := add(binding{env, s}, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range categories {
+		found := false
+		for i := range all {
+			if all[i].Category == c {
+				found = true
+				add(binding{envName(all[i].Name), &all[i]}, false)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("category %q: %w", c, api.ErrNotFound)
+		}
+	}
+	return out, nil
+}
+
+// exec runs argv with env, forwarding signals, and passes its exit status on.
+func (r *runner) exec(argv, env []string) error {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = r.stdin, r.stdout, r.stderr
+	if r.lines != nil {
+		// the master password was read from piped stdin; hand the child
+		// whatever was buffered after it
+		cmd.Stdin = r.lines
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals...)
+	defer signal.Stop(sigs)
+	// catch rather than ignore terminal signals: an ignored disposition
+	// would be inherited by the child
+	swallowed := make(chan os.Signal, 1)
+	signal.Notify(swallowed, terminalSignals...)
+	defer signal.Stop(swallowed)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				cmd.Process.Signal(sig)
+			case <-swallowed:
+			case <-done:
+				return
+			}
+		}
+	}()
+	err := cmd.Wait()
+	close(done)
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitStatus(exitCode(exitErr.ProcessState))
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"sm-cli/pkg/api"
+)
+
+func TestPlanBindings(t *testing.T) {
+	all := []api.Secret{
+		{ID: "s1", Name: "db-password", Category: "prod"},
+		{ID: "s2", Name: "api-token", Category: "prod"},
+		{ID: "s3", Name: "db-password", Category: "staging"},
+		{ID: "s4", Name: "smtp", Category: "mail"},
+	}
+	type bound struct{ env, id string }
+	tests := []struct {
+		name       string
+		secrets    []string
+		categories []string
+		want       []bound
+		err        error
+	}{
+		{
+			name:    "explicit and derived names",
+			secrets: []string{"PGPASSWORD=prod/db-password", "smtp"},
+			want:    []bound{{"PGPASSWORD", "s1"}, {"SMTP", "s4"}},
+		},
+		{
+			name:       "explicit wins over category",
+			secrets:    []string{"API_TOKEN=smtp"},
+			categories: []string{"prod"},
+			want:       []bound{{"API_TOKEN", "s4"}, {"DB_PASSWORD", "s1"}},
+		},
+		{
+			name:    "same secret bound twice is fine",
+			secrets: []string{"SMTP=smtp", "smtp"},
+			want:    []bound{{"SMTP", "s4"}},
+		},
+		{
+			name:    "conflicting explicit bindings",
+			secrets: []string{"X=smtp", "X=api-token"},
+			err:     errUsage,
+		},
+		{
+			name:    "ambiguous bare name",
+			secrets: []string{"db-password"},
+			err:     errUsage,
+		},
+		{
+			name:    "bad variable name",
+			secrets: []string{"1X=smtp"},
+			err:     errUsage,
+		},
+		{
+			name:    "unknown secret",
+			secrets: []string{"nope"},
+			err:     api.ErrNotFound,
+		},
+		{
+			name:       "unknown category",
+			categories: []string{"nope"},
+			err:        api.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planBindings(all, tt.secrets, tt.categories)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d bindings, want %d", len(got), len(tt.want))
+			}
+			for i, b := range got {
+				if (bound{b.env, b.secret.ID}) != tt.want[i] {
+					t.Errorf("binding %d = %s=%s, want %v", i, b.env, b.secret.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"sm-cli/pkg/api"
+)
+
+func (r *runner) secrets(ctx context.Context, args []string) error {
+	sub, args, err := subcommand("secrets", args, "list", "get", "create", "update", "delete")
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		return r.secretsList(ctx, args)
+	case "get":
+		return r.secretsGet(ctx, args)
+	case "create":
+		return r.secretsCreate(ctx, args)
+	case "update":
+		return r.secretsUpdate(ctx, args)
+	default:
+		return r.secretsDelete(ctx, args)
+	}
+}
+
+func (r *runner) secretsList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("secrets list", flag.ContinueOnError)
+	page := fs.Int("page", 1, "page to fetch")
+	limit := fs.Int("limit", 20, "secrets per page")
+	all := fs.Bool("all", false, "fetch every page")
+	category := fs.String("category", "", "only show secrets in this category")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+
+	list := &api.SecretList{}
+	if *all {
+		secrets, err := r.client.AllSecrets(ctx)
+		if err != nil {
+			return err
+		}
+		list.Secrets = secrets
+		list.Page = api.Page{Page: 1, Limit: len(secrets), Total: len(secrets), TotalPages: 1}
+	} else {
+		var err error
+		if list, err = r.client.GetSecrets(ctx, *page, *limit); err != nil {
+			return err
+		}
+	}
+	if *category != "" {
+		filtered := list.Secrets[:0]
+		for _, s := range list.Secrets {
+			if s.Category == *category {
+				filtered = append(filtered, s)
+			}
+		}
+		list.Secrets = filtered
+	}
+
+	if r.out.format == formatEnv {
+		// env output needs the decrypted values
+		master, err := r.readSecret("Master password")
+		if err != nil {
+			return err
+		}
+		for i := range list.Secrets {
+			full, err := r.client.GetSecret(ctx, list.Secrets[i].ID, master)
+			if err != nil {
+				return err
+			}
+			list.Secrets[i].Value = full.Value
+		}
+	}
+
+	if err := r.out.print(list, secretTable(list.Secrets), secretEnv(list.Secrets)); err != nil {
+		return err
+	}
+	if !r.out.structured() && !*all {
+		fmt.Fprintf(r.stderr, "page %d of %d (%d total)\n", list.Page.Page, list.TotalPages, list.Total)
+	}
+	return nil
+}
+
+func (r *runner) secretsGet(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("secrets get", flag.ContinueOnError)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("secrets get: expected exactly one secret name or id")
+	}
+	s, err := r.resolveSecret(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	master, err := r.readSecret("Master password")
+	if err != nil {
+		return err
+	}
+	full, err := r.client.GetSecret(ctx, s.ID, master)
+	if err != nil {
+		return err
+	}
+	if r.out.format == "" {
+		_, err = fmt.Fprintln(r.stdout, full.Value)
+		return err
+	}
+	return r.out.print(full, secretTable([]api.Secret{*full}), secretEnv([]api.Secret{*full}))
+}
+
+func (r *runner) secretsCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("secrets create", flag.ContinueOnError)
+	category := fs.String("category", r.cfg.DefaultCategory, "secret category")
+	description := fs.String("description", "", "secret description")
+	valueFile := fs.String("value-file", "", `read the value from a file ("-" for stdin) instead of prompting`)
+	idemKey := fs.String("idempotency-key", "", "reuse the key of an earlier, interrupted create")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("secrets create: expected exactly one secret name")
+	}
+	if err := r.out.rejectEnv(); err != nil {
+		return err
+	}
+
+	master, err := r.readSecret("Master password")
+	if err != nil {
+		return err
+	}
+	value, err := r.readValue(*valueFile)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return usageErrorf("secrets create: value is required")
+	}
+
+	key := *idemKey
+	if key == "" {
+		key = api.NewIdempotencyKey()
+	}
+	s, err := r.client.CreateSecret(api.WithIdempotencyKey(ctx, key), pos[0], value, *category, *description, master)
+	if err != nil {
+		return fmt.Errorf("%w (retry with --idempotency-key %s)", err, key)
+	}
+	if r.out.structured() {
+		return r.out.print(s, table{}, nil)
+	}
+	fmt.Fprintf(r.stdout, "Created secret %s (%s)\n", s.Name, s.ID)
+	return nil
+}
+
+func (r *runner) secretsUpdate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("secrets update", flag.ContinueOnError)
+	name := fs.String("name", "", "rename the secret")
+	category := fs.String("category", "", "new category")
+	description := fs.String("description", "", "new description")
+	valueFile := fs.String("value-file", "", `read the new value from a file ("-" for stdin)`)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("secrets update: expected exactly one secret name or id")
+	}
+	if err := r.out.rejectEnv(); err != nil {
+		return err
+	}
+
+	s, err := r.resolveSecret(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	master, err := r.readSecret("Master password")
+	if err != nil {
+		return err
+	}
+	// PUT replaces the whole secret, so start from the current values
+	cur, err := r.client.GetSecret(ctx, s.ID, master)
+	if err != nil {
+		return err
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["name"] {
+		cur.Name = *name
+	}
+	if set["category"] {
+		cur.Category = *category
+	}
+	if set["description"] {
+		cur.Description = *description
+	}
+	if set["value-file"] {
+		if cur.Value, err = r.readValue(*valueFile); err != nil {
+			return err
+		}
+		if cur.Value == "" {
+			return usageErrorf("secrets update: value is required")
+		}
+	}
+
+	updated, err := r.client.UpdateSecret(ctx, s.ID, cur.Name, cur.Value, cur.Category, cur.Description, master)
+	if err != nil {
+		return err
+	}
+	if r.out.structured() {
+		return r.out.print(updated, table{}, nil)
+	}
+	fmt.Fprintf(r.stdout, "Updated secret %s (%s)\n", updated.Name, s.ID)
+	return nil
+}
+
+func (r *runner) secretsDelete(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("secrets delete", flag.ContinueOnError)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := r.requireToken(); err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("secrets delete: expected exactly one secret name or id")
+	}
+	if err := r.out.rejectEnv(); err != nil {
+		return err
+	}
+	s, err := r.resolveSecret(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if err := r.client.DeleteSecret(ctx, s.ID); err != nil {
+		return err
+	}
+	if r.out.structured() {
+		return r.out.print(s, table{}, nil)
+	}
+	fmt.Fprintf(r.stdout, "Deleted secret %s (%s)\n", s.Name, s.ID)
+	return nil
+}
+
+// resolveSecret finds a secret by name, id or CATEGORY/NAME.
+func (r *runner) resolveSecret(ctx context.Context, ref string) (*api.Secret, error) {
+	all, err := r.client.AllSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findSecret(all, ref)
+}
+
+// findSecret looks ref up as an exact name, an id, then CATEGORY/NAME. A bare
+// name shared by secrets in several categories is an error rather than a
+// guess.
+func findSecret(all []api.Secret, ref string) (*api.Secret, error) {
+	var named []*api.Secret
+	for i := range all {
+		if all[i].Name == ref {
+			named = append(named, &all[i])
+		}
+	}
+	switch len(named) {
+	case 0:
+	case 1:
+		return named[0], nil
+	default:
+		refs := make([]string, len(named))
+		for i, s := range named {
+			refs[i] = s.Category + "/" + s.Name
+		}
+		return nil, fmt.Errorf("secret %q is ambiguous, use one of %s or an id: %w", ref, strings.Join(refs, ", "), errUsage)
+	}
+	for i := range all {
+		if all[i].ID == ref {
+			return &all[i], nil
+		}
+	}
+	if category, name, ok := strings.Cut(ref, "/"); ok {
+		for i := range all {
+			if all[i].Category == category && all[i].Name == name {
+				return &all[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("secret %q: %w", ref, api.ErrNotFound)
+}
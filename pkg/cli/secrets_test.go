@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"sm-cli/pkg/api"
+	"sm-cli/pkg/config"
+)
+
+func TestFindSecret(t *testing.T) {
+	all := []api.Secret{
+		{ID: "s1", Name: "db", Category: "staging"},
+		{ID: "s2", Name: "db", Category: "prod"},
+		{ID: "s3", Name: "token", Category: "prod"},
+		{ID: "s4", Name: "s1", Category: "odd"},
+	}
+	tests := []struct {
+		ref    string
+		wantID string
+		err    error
+	}{
+		{ref: "token", wantID: "s3"},
+		{ref: "prod/db", wantID: "s2"},
+		{ref: "staging/db", wantID: "s1"},
+		{ref: "s2", wantID: "s2"},
+		{ref: "s1", wantID: "s4"}, // names win over ids
+		{ref: "dev/db", err: api.ErrNotFound},
+		{ref: "missing", err: api.ErrNotFound},
+	}
+	for _, tt := range tests {
+		s, err := findSecret(all, tt.ref)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("findSecret(%q): err = %v, want %v", tt.ref, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || s.ID != tt.wantID {
+			t.Errorf("findSecret(%q) = %v, %v; want %s", tt.ref, s, err, tt.wantID)
+		}
+	}
+}
+
+func TestFindSecretAmbiguous(t *testing.T) {
+	all := []api.Secret{
+		{ID: "s1", Name: "db", Category: "staging"},
+		{ID: "s2", Name: "db", Category: "prod"},
+	}
+	s, err := findSecret(all, "db")
+	if err == nil {
+		t.Fatalf("findSecret(db) = %s, want an ambiguity error", s.ID)
+	}
+	if !errors.Is(err, errUsage) {
+		t.Errorf("err = %v, want a usage error", err)
+	}
+}
+
+func TestSecretsChangesRejectEnvOutputFirst(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"secrets": [{"id": "s1", "name": "db"}]}`))
+	}))
+	defer srv.Close()
+
+	for _, args := range [][]string{{"create", "db"}, {"update", "db"}, {"delete", "db"}} {
+		r := &runner{
+			cfg:    &config.Config{},
+			client: api.NewClient(api.WithBaseURL(srv.URL), api.WithToken("t")),
+			stdin:  strings.NewReader("master\nvalue\n"),
+			stdout: io.Discard,
+			stderr: io.Discard,
+			out:    &printer{w: io.Discard, format: formatEnv},
+		}
+		if err := r.secrets(context.Background(), args); !errors.Is(err, errUsage) {
+			t.Errorf("secrets %s: err = %v, want a usage error", args[0], err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("%d requests sent before rejecting --output env", requests)
+	}
+}
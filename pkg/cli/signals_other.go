@@ -0,0 +1,16 @@
+//go:build !unix
+
+package cli
+
+import "os"
+
+// forwardedSignals are relayed from sm-cli to the child started by run.
+var forwardedSignals []os.Signal
+
+// terminalSignals reach the child from the console directly; run only keeps
+// them from killing sm-cli.
+var terminalSignals = []os.Signal{os.Interrupt}
+
+func exitCode(ps *os.ProcessState) int {
+	return ps.ExitCode()
+}
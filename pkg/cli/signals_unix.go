@@ -0,0 +1,26 @@
+//go:build unix
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// forwardedSignals are relayed from sm-cli to the child started by run.
+var forwardedSignals = []os.Signal{
+	syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2,
+}
+
+// terminalSignals come from the tty and reach the child directly, since it
+// shares sm-cli's process group; run only keeps them from killing sm-cli.
+var terminalSignals = []os.Signal{syscall.SIGINT, syscall.SIGQUIT, syscall.SIGWINCH}
+
+// exitCode follows the shell convention of 128+N for a child killed by
+// signal N.
+func exitCode(ps *os.ProcessState) int {
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return ps.ExitCode()
+}
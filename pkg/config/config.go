@@ -0,0 +1,237 @@
+// Package config resolves sm-cli settings from the config file, environment
+// variables and command-line flags into a single Config.
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sm-cli/pkg/api"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables that override the config file.
+const (
+	EnvBackendURL = "SM_BACKEND_URL"
+	EnvAPIKey     = "SM_API_KEY"
+	EnvProfile    = "SM_PROFILE"
+	EnvConfig     = "SM_CONFIG"
+)
+
+// DefaultProfile is used when no profile is selected anywhere.
+const DefaultProfile = "default"
+
+// File is the on-disk layout of config.yaml. The top-level connection
+// settings form the "default" profile; named profiles live under profiles.
+type File struct {
+	Profile  `yaml:",inline"`
+	Active   string              `yaml:"profile,omitempty"` // selected profile name
+	Timeout  string              `yaml:"timeout,omitempty"` // Go duration, e.g. "10s"
+	Output   string              `yaml:"output,omitempty"`  // default --output format
+	Store    string              `yaml:"credential_store,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// Profile holds the settings for one vault backend and account.
+type Profile struct {
+	BackendURL      string `yaml:"backend_url,omitempty"`
+	APIKey          string `yaml:"api_key,omitempty"`
+	Credential      string `yaml:"credential,omitempty"` // "env:NAME" or "file:PATH"
+	DefaultCategory string `yaml:"default_category,omitempty"`
+	TLS             TLS    `yaml:"tls,omitempty"`
+}
+
+// TLS customises certificate verification for a profile.
+type TLS struct {
+	CAFile             string `yaml:"ca_file,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// Overrides are the values given on the command line; empty fields are unset.
+type Overrides struct {
+	Path       string
+	BackendURL string
+	APIKey     string
+	Profile    string
+}
+
+// Config is the fully resolved configuration shared by pkg/app and pkg/api.
+// Precedence, highest first: flags, environment, config file, defaults.
+type Config struct {
+	Path            string // config file in use, whether or not it exists
+	BackendURL      string
+	APIKey          string
+	Profile         string
+	DefaultCategory string
+	TLS             TLS
+	Timeout         time.Duration
+	Output          string
+	CredentialStore string // auto, secret-service, file or none
+
+	// CredentialErr explains why the profile credential could not be
+	// resolved; it is only reported once a command needs to authenticate.
+	CredentialErr error
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/sm-cli/config.yaml, falling back to
+// ~/.config when XDG_CONFIG_HOME is unset.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(".config", "sm-cli", "config.yaml")
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "sm-cli", "config.yaml")
+}
+
+// ReadFile parses the config file at path. A missing file yields an empty File.
+func ReadFile(path string) (*File, error) {
+	f := &File{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Load resolves the configuration for this invocation.
+func Load(o Overrides) (*Config, error) {
+	path := first(o.Path, os.Getenv(EnvConfig), DefaultPath())
+	f, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := first(o.Profile, os.Getenv(EnvProfile), f.Active, DefaultProfile)
+	p, ok := f.Profiles[name]
+	if !ok {
+		if name != DefaultProfile {
+			return nil, fmt.Errorf("unknown profile %q in %s", name, path)
+		}
+		p = &f.Profile
+	}
+	key := p.APIKey
+	var credErr error
+	if key == "" && p.Credential != "" {
+		if key, err = ResolveCredential(p.Credential); err != nil {
+			credErr = fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+
+	c := &Config{
+		Path:            path,
+		BackendURL:      first(o.BackendURL, os.Getenv(EnvBackendURL), p.BackendURL, api.DefaultBaseURL),
+		APIKey:          first(o.APIKey, os.Getenv(EnvAPIKey), key),
+		Profile:         name,
+		DefaultCategory: p.DefaultCategory,
+		TLS:             p.TLS,
+		Output:          f.Output,
+		CredentialStore: f.Store,
+	}
+	if c.APIKey == "" {
+		c.CredentialErr = credErr
+	}
+	if f.Timeout != "" {
+		if c.Timeout, err = time.ParseDuration(f.Timeout); err != nil {
+			return nil, fmt.Errorf("%s: timeout: %w", path, err)
+		}
+	}
+	if err := ValidateBackendURL(c.BackendURL); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ValidateBackendURL reports whether u can be used as a backend URL.
+func ValidateBackendURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("backend URL %q must start with http:// or https://", u)
+	}
+	return nil
+}
+
+// CredentialsPath is the encrypted credentials file used when the Secret
+// Service is not available. It lives next to the config file.
+func (c *Config) CredentialsPath() string {
+	return filepath.Join(filepath.Dir(c.Path), "credentials.enc")
+}
+
+// ClientOptions returns the api options matching c.
+func (c *Config) ClientOptions() ([]api.Option, error) {
+	opts := []api.Option{api.WithBaseURL(c.BackendURL)}
+	if c.APIKey != "" {
+		opts = append(opts, api.WithToken(c.APIKey))
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, api.WithTimeout(c.Timeout))
+	}
+	if c.TLS.CAFile != "" || c.TLS.InsecureSkipVerify {
+		tc := &tls.Config{InsecureSkipVerify: c.TLS.InsecureSkipVerify}
+		if c.TLS.CAFile != "" {
+			pem, err := os.ReadFile(c.TLS.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("profile %q: %w", c.Profile, err)
+			}
+			tc.RootCAs = x509.NewCertPool()
+			if !tc.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("profile %q: no certificates in %s", c.Profile, c.TLS.CAFile)
+			}
+		}
+		opts = append(opts, api.WithTLSConfig(tc))
+	}
+	return opts, nil
+}
+
+// ResolveCredential dereferences a profile credential reference:
+// "env:NAME" reads an environment variable, "file:PATH" the first line of a file.
+func ResolveCredential(ref string) (string, error) {
+	scheme, arg, ok := strings.Cut(ref, ":")
+	if !ok {
+		return "", fmt.Errorf("credential %q: expected env:NAME or file:PATH", ref)
+	}
+	switch scheme {
+	case "env":
+		v := os.Getenv(arg)
+		if v == "" {
+			return "", fmt.Errorf("credential %q: %s is not set", ref, arg)
+		}
+		return v, nil
+	case "file":
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			return "", fmt.Errorf("credential %q: %w", ref, err)
+		}
+		line, _, _ := strings.Cut(string(b), "\n")
+		return strings.TrimSpace(line), nil
+	}
+	return "", fmt.Errorf("credential %q: unknown scheme %q", ref, scheme)
+}
+
+// first returns the first non-empty value.
+func first(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
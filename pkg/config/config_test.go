@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, "backend_url: http://file.example\napi_key: file-key\noutput: json\n")
+	tests := []struct {
+		name    string
+		env     map[string]string
+		o       Overrides
+		wantURL string
+		wantKey string
+	}{
+		{"file", nil, Overrides{}, "http://file.example", "file-key"},
+		{"env over file", map[string]string{EnvBackendURL: "http://env.example", EnvAPIKey: "env-key"}, Overrides{},
+			"http://env.example", "env-key"},
+		{"flag over env", map[string]string{EnvBackendURL: "http://env.example", EnvAPIKey: "env-key"},
+			Overrides{BackendURL: "https://flag.example", APIKey: "flag-key"}, "https://flag.example", "flag-key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvBackendURL, "")
+			t.Setenv(EnvAPIKey, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			tt.o.Path = path
+			c, err := Load(tt.o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.BackendURL != tt.wantURL || c.APIKey != tt.wantKey {
+				t.Errorf("got %s, %s; want %s, %s", c.BackendURL, c.APIKey, tt.wantURL, tt.wantKey)
+			}
+			if c.Output != "json" {
+				t.Errorf("Output = %q, want json", c.Output)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPathFromEnv(t *testing.T) {
+	path := writeConfig(t, "backend_url: https://from-env-path.example\n")
+	t.Setenv(EnvConfig, path)
+	t.Setenv(EnvBackendURL, "")
+	c, err := Load(Overrides{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Path != path || c.BackendURL != "https://from-env-path.example" {
+		t.Errorf("got %s from %s", c.BackendURL, c.Path)
+	}
+}
+
+func TestLoadMissingFileUsesDefaults(t *testing.T) {
+	t.Setenv(EnvBackendURL, "")
+	t.Setenv(EnvAPIKey, "")
+	c, err := Load(Overrides{Path: filepath.Join(t.TempDir(), "none.yaml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BackendURL == "" || c.APIKey != "" {
+		t.Errorf("got %q, %q; want the default backend and no key", c.BackendURL, c.APIKey)
+	}
+}
+
+func TestLoadRejectsBadURL(t *testing.T) {
+	t.Setenv(EnvBackendURL, "")
+	path := writeConfig(t, "backend_url: vault.example.com\n")
+	if _, err := Load(Overrides{Path: path}); err == nil {
+		t.Error("Load accepted a backend URL without a scheme")
+	}
+}
+
+func TestValidateBackendURL(t *testing.T) {
+	for u, ok := range map[string]bool{
+		"https://vault.example.com": true,
+		"http://localhost:8080/api": true,
+		"vault.example.com":         false,
+		"ftp://vault.example.com":   false,
+		"https://":                  false,
+		"":                          false,
+	} {
+		if err := ValidateBackendURL(u); (err == nil) != ok {
+			t.Errorf("ValidateBackendURL(%q) = %v", u, err)
+		}
+	}
+}
+
+func TestResolveCredential(t *testing.T) {
+	t.Setenv("SM_TEST_TOKEN", "from-env")
+	file := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(file, []byte(" from-file \nignored\n"), 0o600)
+
+	for ref, want := range map[string]string{"env:SM_TEST_TOKEN": "from-env", "file:" + file: "from-file"} {
+		if got, err := ResolveCredential(ref); err != nil || got != want {
+			t.Errorf("ResolveCredential(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	for _, ref := range []string{"env:SM_TEST_UNSET", "file:/nonexistent", "vault:x", "plain"} {
+		if _, err := ResolveCredential(ref); err == nil {
+			t.Errorf("ResolveCredential(%q) succeeded", ref)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package credstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// EnvPassphrase supplies the file passphrase non-interactively.
+const EnvPassphrase = "SM_CREDENTIALS_PASSPHRASE"
+
+// ErrBadPassphrase is returned when the credentials file cannot be decrypted.
+var ErrBadPassphrase = errors.New("wrong passphrase or corrupted credentials file")
+
+const (
+	fileVersion   = 1
+	kdfIterations = 600000
+)
+
+// sealed is the on-disk JSON envelope of the credentials file.
+type sealed struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// FileStore keeps tokens in a single AES-256-GCM encrypted file, with the key
+// derived from a passphrase via PBKDF2-SHA256. It is meant for headless
+// machines without a Secret Service.
+type FileStore struct {
+	path string
+
+	// Passphrase is asked for the first time the file is read or written,
+	// unless EnvPassphrase is set; the result is cached for the lifetime of
+	// the store.
+	Passphrase func() (string, error)
+
+	mu   sync.Mutex
+	pass string
+}
+
+// NewFileStore returns a store backed by the file at path.
+func NewFileStore(path string, passphrase func() (string, error)) *FileStore {
+	return &FileStore{path: path, Passphrase: passphrase}
+}
+
+func (f *FileStore) Name() string { return BackendFile }
+
+// Path returns the credentials file location.
+func (f *FileStore) Path() string { return f.path }
+
+// Unlocked reports whether the passphrase is known, so reads and writes will
+// not prompt.
+func (f *FileStore) Unlocked() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pass != "" || os.Getenv(EnvPassphrase) != ""
+}
+
+// Exists reports whether the credentials file has been created yet.
+func (f *FileStore) Exists() bool {
+	_, err := os.Stat(f.path)
+	return err == nil
+}
+
+func (f *FileStore) Get(profile string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.Exists() {
+		return "", ErrNotFound
+	}
+	tokens, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	t, ok := tokens[profile]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return t, nil
+}
+
+func (f *FileStore) Set(profile, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tokens, err := f.load()
+	if err != nil {
+		return err
+	}
+	tokens[profile] = token
+	return f.save(tokens)
+}
+
+func (f *FileStore) Delete(profile string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.Exists() {
+		return nil
+	}
+	tokens, err := f.load()
+	if err != nil {
+		return err
+	}
+	delete(tokens, profile)
+	if len(tokens) == 0 {
+		return os.Remove(f.path)
+	}
+	return f.save(tokens)
+}
+
+func (f *FileStore) passphrase() (string, error) {
+	if f.pass != "" {
+		return f.pass, nil
+	}
+	if p := os.Getenv(EnvPassphrase); p != "" {
+		f.pass = p
+		return p, nil
+	}
+	if f.Passphrase == nil {
+		return "", fmt.Errorf("credentials file %s needs a passphrase", f.path)
+	}
+	p, err := f.Passphrase()
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", fmt.Errorf("empty passphrase")
+	}
+	f.pass = p
+	return p, nil
+}
+
+// load decrypts the file; a missing file yields an empty map.
+func (f *FileStore) load() (map[string]string, error) {
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var env sealed
+	if err := json.Unmarshal(b, &env); err != nil || env.Version != fileVersion {
+		return nil, fmt.Errorf("%s: unsupported credentials file", f.path)
+	}
+	pass, err := f.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(pass, env.Salt, env.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		// forget the passphrase so the next attempt asks again
+		f.pass = ""
+		return nil, ErrBadPassphrase
+	}
+	tokens := map[string]string{}
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return tokens, nil
+}
+
+func (f *FileStore) save(tokens map[string]string) error {
+	pass, err := f.passphrase()
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	env := sealed{Version: fileVersion, Iterations: kdfIterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(pass, env.Salt, env.Iterations)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return err
+	}
+	env.Data = gcm.Seal(nil, env.Nonce, plain, nil)
+
+	b, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+func newGCM(pass string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, pass, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,73 @@
+package credstore
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func passphrase(p string) func() (string, error) {
+	return func() (string, error) { return p, nil }
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	t.Setenv(EnvPassphrase, "")
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	fs := NewFileStore(path, passphrase("correct horse"))
+	if _, err := fs.Get("default"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get on a missing file: err = %v, want ErrNotFound", err)
+	}
+	if err := fs.Set("default", "tok-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Set("work", "tok-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a fresh store has to decrypt from disk
+	again := NewFileStore(path, passphrase("correct horse"))
+	for profile, want := range map[string]string{"default": "tok-1", "work": "tok-2"} {
+		if got, err := again.Get(profile); err != nil || got != want {
+			t.Errorf("Get(%s) = %q, %v; want %q", profile, got, err, want)
+		}
+	}
+	if _, err := again.Get("other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(other): err = %v, want ErrNotFound", err)
+	}
+
+	if err := again.Delete("default"); err != nil {
+		t.Fatal(err)
+	}
+	if err := again.Delete("work"); err != nil {
+		t.Fatal(err)
+	}
+	if again.Exists() {
+		t.Error("file still exists after the last token was deleted")
+	}
+}
+
+func TestFileStoreWrongPassphrase(t *testing.T) {
+	t.Setenv(EnvPassphrase, "")
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	if err := NewFileStore(path, passphrase("right")).Set("default", "tok"); err != nil {
+		t.Fatal(err)
+	}
+	wrong := NewFileStore(path, passphrase("wrong"))
+	if _, err := wrong.Get("default"); !errors.Is(err, ErrBadPassphrase) {
+		t.Fatalf("err = %v, want ErrBadPassphrase", err)
+	}
+	if wrong.Unlocked() {
+		t.Error("a rejected passphrase is still cached")
+	}
+}
+
+func TestFileStoreEnvPassphrase(t *testing.T) {
+	t.Setenv(EnvPassphrase, "from-env")
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	if err := NewFileStore(path, nil).Set("default", "tok"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := NewFileStore(path, passphrase("from-env")).Get("default"); err != nil || got != "tok" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,54 @@
+package credstore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const serviceAttr = "sm-cli"
+
+// secretService stores tokens in the freedesktop Secret Service (GNOME
+// Keyring, KWallet) through libsecret's secret-tool, which talks D-Bus for us.
+type secretService struct{}
+
+func (secretService) Name() string { return BackendSecretService }
+
+func (secretService) Get(profile string) (string, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", serviceAttr, "profile", profile)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// lookup exits 1 without output when nothing matches
+		if errors.As(err, &exitErr) && stderr.Len() == 0 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secret-tool lookup: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimRight(string(out), "\n")
+	if token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (secretService) Set(profile, token string) error {
+	cmd := exec.Command("secret-tool", "store", "--label=sm-cli ("+profile+")", "service", serviceAttr, "profile", profile)
+	cmd.Stdin = strings.NewReader(token)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (secretService) Delete(profile string) error {
+	cmd := exec.Command("secret-tool", "clear", "service", serviceAttr, "profile", profile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool clear: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
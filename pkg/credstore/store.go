@@ -0,0 +1,69 @@
+// Package credstore persists login tokens between sm-cli sessions, either in
+// the desktop Secret Service (via D-Bus) or in a passphrase-encrypted file.
+package credstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ErrNotFound is returned by Get when no token is stored for the profile.
+var ErrNotFound = errors.New("no stored credentials")
+
+// Store keeps one token per configuration profile.
+type Store interface {
+	Get(profile string) (string, error)
+	Set(profile, token string) error
+	Delete(profile string) error
+	// Name identifies the backend in messages, e.g. "secret-service".
+	Name() string
+}
+
+// Backend names accepted by Open.
+const (
+	BackendAuto          = "auto"
+	BackendSecretService = "secret-service"
+	BackendFile          = "file"
+	BackendNone          = "none"
+)
+
+// Open returns the store for backend. "auto" (or empty) prefers the Secret
+// Service when a session bus and secret-tool are available and falls back to
+// the encrypted file at path. passphrase is only used by the file backend.
+func Open(backend, path string, passphrase func() (string, error)) (Store, error) {
+	switch backend {
+	case "", BackendAuto:
+		if secretServiceAvailable() {
+			return secretService{}, nil
+		}
+		return NewFileStore(path, passphrase), nil
+	case BackendSecretService:
+		if !secretServiceAvailable() {
+			return nil, fmt.Errorf("secret service unavailable: need secret-tool and a D-Bus session")
+		}
+		return secretService{}, nil
+	case BackendFile:
+		return NewFileStore(path, passphrase), nil
+	case BackendNone:
+		return noStore{}, nil
+	}
+	return nil, fmt.Errorf("unknown credential store %q", backend)
+}
+
+func secretServiceAvailable() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+// noStore disables persistence.
+type noStore struct{}
+
+func (noStore) Get(string) (string, error) { return "", ErrNotFound }
+func (noStore) Set(string, string) error   { return nil }
+func (noStore) Delete(string) error        { return nil }
+func (noStore) Name() string               { return BackendNone }
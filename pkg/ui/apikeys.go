@@ -0,0 +1,279 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sm-cli/pkg/api"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const apiKeysPageSize = 20
+
+// apiKeyFilters are the status filters cycled with f; "" shows every key.
+var apiKeyFilters = []string{"", "active", "revoked"}
+
+// apiKeysBrowser is the state of the API key management screen.
+type apiKeysBrowser struct {
+	u      *UI
+	list   *api.APIKeyList
+	view   listView
+	filter int // index into apiKeyFilters
+}
+
+// ShowAPIKeys opens the API key browser at page and returns when the user
+// leaves it.
+func (u *UI) ShowAPIKeys(page int) {
+	b := &apiKeysBrowser{u: u}
+	if !b.load(page) {
+		return
+	}
+	b.run()
+}
+
+// load fetches page with the current filter and reports whether it
+// succeeded. Failures are queued for the status bar.
+func (b *apiKeysBrowser) load(page int) bool {
+	var list *api.APIKeyList
+	err := b.u.runAuthed("Loading API keys...", func(ctx context.Context) error {
+		var err error
+		list, err = api.Default().GetAPIKeys(ctx, page, apiKeysPageSize, apiKeyFilters[b.filter])
+		return err
+	})
+	if err != nil {
+		b.u.handleAuthError(err)
+		b.u.setStatus("Failed to load API keys: " + errorText(err))
+		return false
+	}
+	b.list = list
+	b.view.cursor, b.view.offset = 0, 0
+	b.view.setRows(b.rows())
+	return true
+}
+
+// reload refreshes the current page after a change, keeping the cursor.
+func (b *apiKeysBrowser) reload() {
+	cursor := b.view.cursor
+	if b.load(b.list.Page.Page) {
+		b.view.move(cursor)
+	}
+}
+
+func (b *apiKeysBrowser) rows() []string {
+	rows := make([]string, 0, len(b.list.APIKeys))
+	for _, k := range b.list.APIKeys {
+		lastUsed := "never"
+		if k.LastUsedAt != nil {
+			lastUsed = formatDate(*k.LastUsedAt)
+		}
+		rows = append(rows, fmt.Sprintf(" %s %s %s %s %s",
+			fit(k.Name, 24), fit(k.Prefix, 12), fit(formatDate(k.CreatedAt), 17), fit(lastUsed, 17), k.Status))
+	}
+	return rows
+}
+
+// selected returns the key under the cursor, or nil on an empty page.
+func (b *apiKeysBrowser) selected() *api.APIKey {
+	if len(b.list.APIKeys) == 0 {
+		return nil
+	}
+	return &b.list.APIKeys[b.view.cursor]
+}
+
+func (b *apiKeysBrowser) render() {
+	s := b.u.s
+	s.Clear()
+	w, h := s.Size()
+	styleTitle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+	styleHeader := tcell.StyleDefault.Bold(true)
+	styleHint := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+
+	b.u.drawText(2, 1, " API Keys ", styleTitle)
+	filter := apiKeyFilters[b.filter]
+	if filter == "" {
+		filter = "all"
+	}
+	b.u.drawText(14, 1, "Showing: "+filter, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	b.u.drawText(2, 3, fmt.Sprintf(" %s %s %s %s %s", fit("NAME", 24), fit("PREFIX", 12), fit("CREATED", 17), fit("LAST USED", 17), "STATUS"), styleHeader)
+
+	listH := h - 8
+	if len(b.list.APIKeys) == 0 {
+		b.u.drawText(4, 5, "(no API keys on this page)", tcell.StyleDefault.Foreground(tcell.ColorDarkGray))
+	} else {
+		b.view.draw(s, 2, 4, w-4, listH)
+	}
+
+	pageInfo := fmt.Sprintf("Page %d of %d  ·  %d keys", b.list.Page.Page, b.list.TotalPages, b.list.Total)
+	b.u.drawText(2, h-3, pageInfo, tcell.StyleDefault)
+	b.u.drawText(2, h-2, "↑↓=Move  PgUp/Dn=Page  n=New  x=Revoke  f=Filter  r=Reload  Esc=Back", styleHint)
+	s.Show()
+	b.u.flushStatus()
+}
+
+func (b *apiKeysBrowser) run() {
+	b.render()
+	for {
+		switch ev := b.u.s.PollEvent().(type) {
+		case *tcell.EventResize:
+			b.render()
+		case *tcell.EventKey:
+			_, h := b.u.s.Size()
+			switch ev.Key() {
+			case tcell.KeyEscape:
+				return
+			case tcell.KeyUp:
+				b.view.move(-1)
+			case tcell.KeyDown:
+				b.view.move(1)
+			case tcell.KeyHome:
+				b.view.move(-len(b.view.rows))
+			case tcell.KeyEnd:
+				b.view.move(len(b.view.rows))
+			case tcell.KeyPgDn:
+				if b.list.HasNext() {
+					b.load(b.list.Page.Page + 1)
+				} else {
+					b.view.move(h)
+				}
+			case tcell.KeyPgUp:
+				if b.list.Page.Page > 1 {
+					b.load(b.list.Page.Page - 1)
+				} else {
+					b.view.move(-h)
+				}
+			case tcell.KeyDelete:
+				b.revoke()
+			case tcell.KeyRune:
+				switch ev.Rune() {
+				case 'q':
+					return
+				case 'j':
+					b.view.move(1)
+				case 'k':
+					b.view.move(-1)
+				case 'r':
+					b.load(b.list.Page.Page)
+				case 'f':
+					prev := b.filter
+					b.filter = (b.filter + 1) % len(apiKeyFilters)
+					if !b.load(1) {
+						b.filter = prev
+					}
+				case 'n':
+					b.create()
+				case 'x':
+					b.revoke()
+				}
+			}
+			b.render()
+		case nil:
+			return
+		}
+	}
+}
+
+// create asks for a name, creates the key and shows it once.
+func (b *apiKeysBrowser) create() {
+	fields := []Field{{Label: "Name", Width: 40}}
+	title := "New API key"
+	var name string
+	for {
+		vals, cancel := PromptForm(b.u.s, title, fields)
+		if cancel {
+			return
+		}
+		name = strings.TrimSpace(vals["Name"])
+		if name != "" {
+			break
+		}
+		title = "New API key - name is required"
+	}
+	var key *api.APIKey
+	err := b.u.runAuthed("Creating API key...", func(ctx context.Context) error {
+		var err error
+		key, err = api.Default().CreateAPIKey(ctx, name)
+		return err
+	})
+	if err != nil {
+		b.u.handleAuthError(err)
+		b.u.setStatus("Failed to create API key: " + errorText(err))
+		return
+	}
+	b.u.showNewAPIKey(key)
+	key.Key = ""
+	b.reload()
+}
+
+// showNewAPIKey displays the full key of a freshly created API key. The
+// backend never returns it again, so the user can copy it before leaving.
+func (u *UI) showNewAPIKey(key *api.APIKey) {
+	copied := ""
+	render := func() {
+		u.s.Clear()
+		_, h := u.s.Size()
+		u.drawText(2, 1, " API key created ", tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue))
+		u.drawText(2, 3, "Name:", tcell.StyleDefault.Foreground(tcell.ColorGreen))
+		u.drawText(15, 3, key.Name, tcell.StyleDefault)
+		u.drawText(2, 5, "Key:", tcell.StyleDefault.Foreground(tcell.ColorGreen))
+		u.drawText(15, 5, key.Key, tcell.StyleDefault.Bold(true))
+		u.drawText(2, 7, "This is the only time the key is shown. Store it somewhere safe now.", tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		u.drawText(2, h-2, "c=Copy  Enter/Esc=Done", tcell.StyleDefault.Foreground(tcell.ColorGreen))
+		u.s.Show()
+		if copied != "" {
+			DrawStatus(u.s, "Copied to clipboard via "+copied)
+		}
+	}
+	render()
+	for {
+		switch ev := u.s.PollEvent().(type) {
+		case *tcell.EventResize:
+			render()
+		case *tcell.EventKey:
+			switch {
+			case ev.Key() == tcell.KeyEnter, ev.Key() == tcell.KeyEscape:
+				if copied == "" && !Confirm(u.s, "Leave without copying the key?", []string{"It cannot be shown again."}) {
+					render()
+					continue
+				}
+				return
+			case ev.Rune() == 'c':
+				copied = copyToClipboard(u.s, key.Key)
+				render()
+			}
+		case nil:
+			return
+		}
+	}
+}
+
+// revoke revokes the selected key after confirmation.
+func (b *apiKeysBrowser) revoke() {
+	k := b.selected()
+	if k == nil {
+		return
+	}
+	if k.Status == "revoked" {
+		b.u.setStatus(k.Name + " is already revoked")
+		return
+	}
+	lines := []string{
+		"Prefix: " + k.Prefix,
+		"Anything still using this key will stop working immediately.",
+	}
+	if !Confirm(b.u.s, "Revoke API key "+k.Name+"?", lines) {
+		return
+	}
+	name := k.Name
+	err := b.u.runAuthed("Revoking "+name+"...", func(ctx context.Context) error {
+		return api.Default().RevokeAPIKey(ctx, k.ID)
+	})
+	if err != nil {
+		b.u.handleAuthError(err)
+		b.u.setStatus("Failed to revoke " + name + ": " + errorText(err))
+		return
+	}
+	b.reload()
+	b.u.setStatus("Revoked " + name)
+}
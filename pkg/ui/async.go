@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sm-cli/pkg/api"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// runCancellable runs fn in the background while msg is shown in the status
+// bar. Pressing Esc cancels the context handed to fn; in that case
+// context.Canceled is returned once fn has unwound.
+func (u *UI) runCancellable(msg string, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+		// wake up PollEvent below
+		u.s.PostEvent(tcell.NewEventInterrupt(nil))
+	}()
+
+	DrawStatus(u.s, msg+" (Esc to cancel)")
+	for {
+		select {
+		case err := <-done:
+			return err
+		default:
+		}
+		switch ev := u.s.PollEvent().(type) {
+		case *tcell.EventKey:
+			if ev.Key() == tcell.KeyEscape {
+				cancel()
+				<-done
+				return context.Canceled
+			}
+		case nil:
+			// screen finalized
+			cancel()
+			return <-done
+		}
+	}
+}
+
+// runAuthed is runCancellable for calls that need a session. When the backend
+// still rejects the token after the client's own refresh attempt, the user is
+// asked to log in again and fn is replayed once.
+func (u *UI) runAuthed(msg string, fn func(ctx context.Context) error) error {
+	err := u.runCancellable(msg, fn)
+	if errors.Is(err, api.ErrUnauthorized) && api.HasToken() && u.reauthenticate() {
+		err = u.runCancellable(msg, fn)
+	}
+	return err
+}
+
+// reauthenticate re-runs the login flow matching the current credential type
+// and reports whether a new token is in place.
+func (u *UI) reauthenticate() bool {
+	old := api.Default().Token()
+	if _, err := api.ParseToken(old); err == nil {
+		u.showPasswordLogin()
+	} else {
+		u.showAPIKeyLogin()
+	}
+	token := api.Default().Token()
+	return token != "" && token != old
+}
+
+// undoTick wakes waitUndo once per second.
+type undoTick struct{}
+
+// waitUndo shows a countdown built by msg in the status bar for d and reports
+// whether it ran out (true) or the user pressed u or Esc to undo (false).
+func (u *UI) waitUndo(d time.Duration, msg func(left time.Duration) string) bool {
+	deadline := time.Now().Add(d)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				u.s.PostEvent(tcell.NewEventInterrupt(undoTick{}))
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return true
+		}
+		drawStatusLine(u.s, msg(left.Round(time.Second)))
+		switch ev := u.s.PollEvent().(type) {
+		case *tcell.EventKey:
+			if ev.Key() == tcell.KeyEscape || ev.Rune() == 'u' || ev.Rune() == 'U' {
+				return false
+			}
+		case nil:
+			return false
+		}
+	}
+}
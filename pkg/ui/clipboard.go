@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// copyToClipboard puts text on the system clipboard using the first available
+// native tool, falling back to the terminal's OSC 52 support through tcell.
+// It returns the mechanism used, for the status bar.
+func copyToClipboard(s tcell.Screen, text string) string {
+	for _, c := range clipboardCommands() {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if cmd.Run() == nil {
+			return c[0]
+		}
+	}
+	s.SetClipboard([]byte(text))
+	return "terminal (OSC 52)"
+}
+
+func clipboardCommands() [][]string {
+	if runtime.GOOS == "darwin" {
+		return [][]string{{"pbcopy"}}
+	}
+	var cmds [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		cmds = append(cmds, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	return cmds
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sm-cli/pkg/api"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// revealTimeout is how long a revealed value stays visible.
+const revealTimeout = 30 * time.Second
+
+// revealTick wakes the detail view once per second while a value is shown.
+type revealTick struct{ gen int }
+
+// secretDetail is the state of the single-secret screen.
+type secretDetail struct {
+	u      *UI
+	sec    *api.Secret
+	value  string    // decrypted value, only held while revealed
+	hideAt time.Time // zero when masked
+	gen    int       // bumps on every reveal so stale ticks are ignored
+	stop   chan struct{}
+}
+
+// ShowSecretDetail shows the metadata of sec with the value masked. The value
+// can be revealed or copied after entering the master password; a revealed
+// value is masked again after revealTimeout. Edits are written back to sec.
+func (u *UI) ShowSecretDetail(sec *api.Secret) {
+	d := &secretDetail{u: u, sec: sec}
+	defer d.mask()
+	d.render()
+	for {
+		switch ev := u.s.PollEvent().(type) {
+		case *tcell.EventResize:
+			d.render()
+		case *tcell.EventInterrupt:
+			if t, ok := ev.Data().(revealTick); ok && t.gen == d.gen && !d.hideAt.IsZero() {
+				if time.Now().After(d.hideAt) {
+					d.mask()
+				}
+				d.render()
+			}
+		case *tcell.EventKey:
+			switch {
+			case ev.Key() == tcell.KeyEscape, ev.Rune() == 'q':
+				return
+			case ev.Rune() == 'v':
+				if d.hideAt.IsZero() {
+					d.reveal()
+				} else {
+					d.mask()
+				}
+				d.render()
+			case ev.Rune() == 'c':
+				d.copy()
+				d.render()
+			case ev.Rune() == 'e', ev.Rune() == 'E':
+				d.mask()
+				edit := d.u.ShowEditSecret
+				if ev.Rune() == 'E' {
+					edit = d.u.ShowEditSecretExternal
+				}
+				if updated := edit(d.sec); updated != nil {
+					d.sec.Name, d.sec.Category, d.sec.Description = updated.Name, updated.Category, updated.Description
+					d.sec.UpdatedAt = updated.UpdatedAt
+				}
+				d.render()
+			}
+		case nil:
+			return
+		}
+	}
+}
+
+// fetch decrypts the value with a master password prompted from the user.
+func (d *secretDetail) fetch() bool {
+	master, ok := d.u.promptMaster(d.sec.Name)
+	if !ok {
+		return false
+	}
+	var full *api.Secret
+	err := d.u.runAuthed("Decrypting...", func(ctx context.Context) error {
+		var err error
+		full, err = api.Default().GetSecret(ctx, d.sec.ID, master)
+		return err
+	})
+	if err != nil {
+		d.u.handleAuthError(err)
+		d.u.setStatus("Cannot decrypt: " + errorText(err))
+		return false
+	}
+	d.value = full.Value
+	return true
+}
+
+func (d *secretDetail) reveal() {
+	if !d.fetch() {
+		return
+	}
+	d.gen++
+	d.hideAt = time.Now().Add(revealTimeout)
+	d.stop = make(chan struct{})
+	gen, s, stop := d.gen, d.u.s, d.stop
+	go func() {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				s.PostEvent(tcell.NewEventInterrupt(revealTick{gen}))
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// mask hides and forgets the value.
+func (d *secretDetail) mask() {
+	d.value = ""
+	d.hideAt = time.Time{}
+	d.gen++
+	if d.stop != nil {
+		close(d.stop)
+		d.stop = nil
+	}
+}
+
+func (d *secretDetail) copy() {
+	value := d.value
+	if value == "" {
+		if !d.fetch() {
+			return
+		}
+		value = d.value
+		if d.hideAt.IsZero() {
+			// copying alone must not leave the value around
+			d.value = ""
+		}
+	}
+	via := copyToClipboard(d.u.s, value)
+	d.u.setStatus("Copied " + d.sec.Name + " to clipboard via " + via)
+}
+
+func (d *secretDetail) render() {
+	u := d.u
+	u.s.Clear()
+	w, h := u.s.Size()
+	styleTitle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+	styleLabel := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	styleHint := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+
+	u.drawText(2, 1, " "+d.sec.Name+" ", styleTitle)
+	rows := [][2]string{
+		{"Name", d.sec.Name},
+		{"Category", d.sec.Category},
+		{"Description", d.sec.Description},
+		{"Created", formatDate(d.sec.CreatedAt)},
+		{"Updated", formatDate(d.sec.UpdatedAt)},
+	}
+	for i, r := range rows {
+		u.drawText(2, 3+i, r[0]+":", styleLabel)
+		u.drawText(15, 3+i, r[1], tcell.StyleDefault)
+	}
+
+	y := 4 + len(rows)
+	u.drawText(2, y, "Value:", styleLabel)
+	if d.hideAt.IsZero() {
+		u.drawText(15, y, "••••••••••••", tcell.StyleDefault.Foreground(tcell.ColorDarkGray))
+	} else {
+		left := time.Until(d.hideAt).Round(time.Second)
+		u.drawText(15, y, fmt.Sprintf("(hides in %s)", left), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		maxY := h - 3
+		for _, line := range wrapLines(d.value, w-17) {
+			y++
+			if y >= maxY {
+				u.drawText(15, y, "…", tcell.StyleDefault)
+				break
+			}
+			u.drawText(15, y, line, tcell.StyleDefault.Bold(true))
+		}
+	}
+
+	hint := "v=Reveal  c=Copy  e=Edit  E=Edit in $EDITOR  Esc=Back"
+	if !d.hideAt.IsZero() {
+		hint = "v=Hide  c=Copy  e=Edit  E=Edit in $EDITOR  Esc=Back"
+	}
+	u.drawText(2, h-2, hint, styleHint)
+	u.s.Show()
+	u.flushStatus()
+}
+
+// wrapLines splits text on newlines and hard-wraps each line at width runes.
+func wrapLines(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		rs := []rune(line)
+		for len(rs) > width {
+			out = append(out, string(rs[:width]))
+			rs = rs[width:]
+		}
+		out = append(out, string(rs))
+	}
+	return out
+}
+
+// formatDate renders a backend timestamp in local time, or "-" when unset.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,319 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// textEditor is a multi-line text buffer with a cursor. Long lines are
+// soft-wrapped at the view width; the text itself is never changed by it.
+type textEditor struct {
+	lines    [][]rune
+	row, col int // cursor, in runes within lines[row]
+	top      int // first visible wrapped row
+	width    int // wrap width of the last draw
+	masked   bool
+	modified bool
+}
+
+func newTextEditor(text string) *textEditor {
+	e := &textEditor{width: 1}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for _, l := range strings.Split(text, "\n") {
+		e.lines = append(e.lines, []rune(l))
+	}
+	e.row = len(e.lines) - 1
+	e.col = len(e.lines[e.row])
+	return e
+}
+
+// text returns the buffer with lines joined by "\n".
+func (e *textEditor) text() string {
+	parts := make([]string, len(e.lines))
+	for i, l := range e.lines {
+		parts[i] = string(l)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (e *textEditor) insert(r rune) {
+	if r == '\n' {
+		e.newline()
+		return
+	}
+	l := e.lines[e.row]
+	l = append(l[:e.col], append([]rune{r}, l[e.col:]...)...)
+	e.lines[e.row] = l
+	e.col++
+	e.modified = true
+}
+
+// insertText inserts pasted text, normalising CRLF and lone CR line endings.
+func (e *textEditor) insertText(s string) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	for _, r := range s {
+		e.insert(r)
+	}
+}
+
+func (e *textEditor) newline() {
+	l := e.lines[e.row]
+	rest := append([]rune(nil), l[e.col:]...)
+	e.lines[e.row] = l[:e.col]
+	e.lines = append(e.lines[:e.row+1], append([][]rune{rest}, e.lines[e.row+1:]...)...)
+	e.row++
+	e.col = 0
+	e.modified = true
+}
+
+func (e *textEditor) backspace() {
+	switch {
+	case e.col > 0:
+		l := e.lines[e.row]
+		e.lines[e.row] = append(l[:e.col-1], l[e.col:]...)
+		e.col--
+	case e.row > 0:
+		prev := e.lines[e.row-1]
+		e.col = len(prev)
+		e.lines[e.row-1] = append(prev, e.lines[e.row]...)
+		e.lines = append(e.lines[:e.row], e.lines[e.row+1:]...)
+		e.row--
+	default:
+		return
+	}
+	e.modified = true
+}
+
+func (e *textEditor) delete() {
+	l := e.lines[e.row]
+	switch {
+	case e.col < len(l):
+		e.lines[e.row] = append(l[:e.col], l[e.col+1:]...)
+	case e.row < len(e.lines)-1:
+		e.lines[e.row] = append(l, e.lines[e.row+1]...)
+		e.lines = append(e.lines[:e.row+1], e.lines[e.row+2:]...)
+	default:
+		return
+	}
+	e.modified = true
+}
+
+func (e *textEditor) left() {
+	switch {
+	case e.col > 0:
+		e.col--
+	case e.row > 0:
+		e.row--
+		e.col = len(e.lines[e.row])
+	}
+}
+
+func (e *textEditor) right() {
+	switch {
+	case e.col < len(e.lines[e.row]):
+		e.col++
+	case e.row < len(e.lines)-1:
+		e.row++
+		e.col = 0
+	}
+}
+
+// segments is the number of wrapped rows line i occupies. A line whose
+// length is a multiple of the width gets an extra row for the cursor.
+func (e *textEditor) segments(i int) int {
+	return len(e.lines[i])/e.width + 1
+}
+
+// up and down move by one wrapped row, keeping the column on screen.
+func (e *textEditor) up() {
+	x := e.col % e.width
+	if e.col >= e.width {
+		e.col -= e.width
+		return
+	}
+	if e.row == 0 {
+		e.col = 0
+		return
+	}
+	e.row--
+	e.col = min((e.segments(e.row)-1)*e.width+x, len(e.lines[e.row]))
+}
+
+func (e *textEditor) down() {
+	x := e.col % e.width
+	if e.col/e.width < e.segments(e.row)-1 {
+		e.col = min(e.col+e.width, len(e.lines[e.row]))
+		return
+	}
+	if e.row == len(e.lines)-1 {
+		e.col = len(e.lines[e.row])
+		return
+	}
+	e.row++
+	e.col = min(x, len(e.lines[e.row]))
+}
+
+// cursorRow is the wrapped row of the cursor, counted from the first line.
+func (e *textEditor) cursorRow() int {
+	n := 0
+	for i := 0; i < e.row; i++ {
+		n += e.segments(i)
+	}
+	return n + e.col/e.width
+}
+
+// size describes the buffer for the status line.
+func (e *textEditor) size() string {
+	text := e.text()
+	return fmt.Sprintf("Ln %d, Col %d  ·  %d lines  ·  %d bytes", e.row+1, e.col+1, len(e.lines), len(text))
+}
+
+// draw renders the buffer into the box at x,y of size w,h and places the
+// terminal cursor.
+func (e *textEditor) draw(s tcell.Screen, x, y, w, h int) {
+	if w < 1 || h < 1 {
+		return
+	}
+	e.width = w
+	cur := e.cursorRow()
+	if cur < e.top {
+		e.top = cur
+	}
+	if cur >= e.top+h {
+		e.top = cur - h + 1
+	}
+
+	style := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
+	vrow := 0
+	for i, l := range e.lines {
+		for seg := 0; seg < e.segments(i); seg++ {
+			sy := vrow - e.top
+			vrow++
+			if sy < 0 {
+				continue
+			}
+			if sy >= h {
+				break
+			}
+			for j := 0; j < w; j++ {
+				ch := ' '
+				if k := seg*w + j; k < len(l) {
+					ch = l[k]
+					if e.masked {
+						ch = '•'
+					} else if ch == '\t' || ch < ' ' {
+						ch = '·'
+					}
+				}
+				s.SetContent(x+j, y+sy, ch, nil, style)
+			}
+		}
+	}
+	for ; vrow-e.top < h; vrow++ {
+		if sy := vrow - e.top; sy >= 0 {
+			for j := 0; j < w; j++ {
+				s.SetContent(x+j, y+sy, ' ', nil, style)
+			}
+		}
+	}
+	s.ShowCursor(x+e.col%w, y+cur-e.top)
+}
+
+// EditText opens a full-screen editor on text and returns the edited text,
+// or cancelled=true when the user leaves with Esc. Enter inserts a newline;
+// Ctrl+S saves. Masked text is shown as bullets until Ctrl+R is pressed.
+func EditText(s tcell.Screen, title, text string, masked bool) (string, bool) {
+	e := newTextEditor(text)
+	e.masked = masked
+	styleTitle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+	styleHint := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	defer s.HideCursor()
+
+	render := func() {
+		s.Clear()
+		w, h := s.Size()
+		for i, r := range []rune(" " + title + " ") {
+			s.SetContent(2+i, 1, r, nil, styleTitle)
+		}
+		e.draw(s, 2, 3, w-4, h-7)
+		for i, r := range []rune(e.size()) {
+			s.SetContent(2+i, h-3, r, nil, tcell.StyleDefault)
+		}
+		hint := "Ctrl+S=Save  Esc=Cancel"
+		if masked {
+			hint += "  Ctrl+R=Show/Hide"
+		}
+		for i, r := range hint {
+			s.SetContent(2+i, h-2, r, nil, styleHint)
+		}
+		s.Show()
+	}
+
+	var paste pasteBuffer
+	render()
+	for {
+		ev := s.PollEvent()
+		if text, ended, took := paste.feed(ev); took {
+			if ended {
+				e.insertText(text)
+				render()
+			}
+			continue
+		}
+		switch ev := ev.(type) {
+		case *tcell.EventResize:
+			render()
+		case *tcell.EventKey:
+			switch ev.Key() {
+			case tcell.KeyEscape:
+				if !e.modified || Confirm(s, "Discard changes?", []string{"Your edits to " + title + " will be lost."}) {
+					return "", true
+				}
+			case tcell.KeyCtrlS:
+				return e.text(), false
+			case tcell.KeyCtrlR:
+				if masked {
+					e.masked = !e.masked
+				}
+			case tcell.KeyEnter:
+				e.newline()
+			case tcell.KeyTab:
+				e.insert('\t')
+			case tcell.KeyBackspace, tcell.KeyBackspace2:
+				e.backspace()
+			case tcell.KeyDelete:
+				e.delete()
+			case tcell.KeyLeft:
+				e.left()
+			case tcell.KeyRight:
+				e.right()
+			case tcell.KeyUp:
+				e.up()
+			case tcell.KeyDown:
+				e.down()
+			case tcell.KeyHome:
+				e.col = 0
+			case tcell.KeyEnd:
+				e.col = len(e.lines[e.row])
+			case tcell.KeyPgUp:
+				_, h := s.Size()
+				for range max(h-8, 1) {
+					e.up()
+				}
+			case tcell.KeyPgDn:
+				_, h := s.Size()
+				for range max(h-8, 1) {
+					e.down()
+				}
+			case tcell.KeyRune:
+				e.insert(ev.Rune())
+			}
+			render()
+		case nil:
+			return "", true
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ui
+
+import "testing"
+
+func TestTextEditorEditing(t *testing.T) {
+	e := newTextEditor("ab\r\ncd")
+	if e.text() != "ab\ncd" || e.row != 1 || e.col != 2 {
+		t.Fatalf("new: %q at %d:%d", e.text(), e.row, e.col)
+	}
+	e.insertText("\r\nef\rgh")
+	if e.text() != "ab\ncd\nef\ngh" || e.row != 3 || e.col != 2 {
+		t.Errorf("paste: %q at %d:%d", e.text(), e.row, e.col)
+	}
+
+	// backspace at the start of a line joins it to the previous one
+	e.col = 0
+	e.backspace()
+	if e.text() != "ab\ncd\nefgh" || e.row != 2 || e.col != 2 {
+		t.Errorf("join: %q at %d:%d", e.text(), e.row, e.col)
+	}
+	// delete at the end of a line pulls up the next
+	e.row, e.col = 0, 2
+	e.delete()
+	if e.text() != "abcd\nefgh" {
+		t.Errorf("delete join: %q", e.text())
+	}
+	e.newline()
+	if e.text() != "ab\ncd\nefgh" || e.row != 1 || e.col != 0 {
+		t.Errorf("split: %q at %d:%d", e.text(), e.row, e.col)
+	}
+	if !e.modified {
+		t.Error("modified not set")
+	}
+}
+
+func TestTextEditorNoOps(t *testing.T) {
+	e := newTextEditor("ab")
+	e.delete()
+	e.row, e.col = 0, 0
+	e.backspace()
+	if e.text() != "ab" || e.modified {
+		t.Errorf("got %q, modified %v", e.text(), e.modified)
+	}
+	e.left()
+	if e.col != 0 {
+		t.Errorf("left at start moved to %d", e.col)
+	}
+}
+
+func TestTextEditorWrappedMovement(t *testing.T) {
+	// width 4: "abcdefghij" wraps as abcd/efgh/ij, "xy" is one row
+	e := newTextEditor("abcdefghij\nxy")
+	e.width = 4
+	e.row, e.col = 0, 1
+	if got := e.cursorRow(); got != 0 {
+		t.Errorf("cursorRow = %d, want 0", got)
+	}
+	e.down()
+	if e.row != 0 || e.col != 5 {
+		t.Errorf("down within line: %d:%d, want 0:5", e.row, e.col)
+	}
+	e.down()
+	e.down()
+	if e.row != 1 || e.col != 1 {
+		t.Errorf("down to next line: %d:%d, want 1:1", e.row, e.col)
+	}
+	if got := e.cursorRow(); got != 3 {
+		t.Errorf("cursorRow = %d, want 3", got)
+	}
+	e.up()
+	if e.row != 0 || e.col != 9 {
+		t.Errorf("up into last segment: %d:%d, want 0:9", e.row, e.col)
+	}
+	e.down()
+	e.down()
+	if e.row != 1 || e.col != 2 {
+		t.Errorf("down on last line goes to end: %d:%d, want 1:2", e.row, e.col)
+	}
+}
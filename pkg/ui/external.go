@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// errNoMemoryDir is returned when no tmpfs directory is available for the
+// temporary copy of a secret.
+var errNoMemoryDir = errors.New("no memory-backed directory for the temporary file (set XDG_RUNTIME_DIR or mount /dev/shm)")
+
+// editorCommand returns $VISUAL or $EDITOR split into words, or vi.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if f := strings.Fields(os.Getenv(env)); len(f) > 0 {
+			return f
+		}
+	}
+	return []string{"vi"}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// editExternal lets the user edit value in their own editor. The tcell
+// screen is suspended while the editor runs. The value is written to a 0600
+// file inside a private 0700 directory on tmpfs, so it never reaches disk;
+// the directory, including any swap or backup files the editor left, is
+// overwritten and removed before returning.
+func editExternal(s tcell.Screen, name, value string) (string, error) {
+	base, err := memoryDir()
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp(base, "sm-cli-")
+	if err != nil {
+		return "", err
+	}
+	defer shredDir(dir)
+
+	file := strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_.")
+	if file == "" {
+		file = "secret"
+	}
+	path := filepath.Join(dir, file)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(value); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	argv := append(editorCommand(), path)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := s.Suspend(); err != nil {
+		return "", err
+	}
+	runErr := cmd.Run()
+	if err := s.Resume(); err != nil {
+		return "", err
+	}
+	s.Sync()
+	if runErr != nil {
+		return "", fmt.Errorf("%s: %w", argv[0], runErr)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	edited := string(b)
+	// most editors add a final newline; keep the value's original ending
+	if !strings.HasSuffix(value, "\n") {
+		edited = strings.TrimSuffix(strings.TrimSuffix(edited, "\n"), "\r")
+	}
+	return edited, nil
+}
+
+// shredDir overwrites every regular file under dir with zeros and removes
+// the directory.
+func shredDir(dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		shred(path)
+		return nil
+	})
+	os.RemoveAll(dir)
+}
+
+func shred(path string) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil {
+		f.WriteAt(make([]byte, info.Size()), 0)
+		f.Sync()
+	}
+}
@@ -1,102 +1,200 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
 )
 
+// Field is one input of a PromptForm; Label doubles as the key of the
+// returned values.
 type Field struct {
 	Label  string
 	Value  string
 	Masked bool
 	Width  int
+
+	// Revealable lets Ctrl+R show and hide a Masked value while editing.
+	Revealable bool
+	// Multiline fields show a one-line summary; Enter or Ctrl+E opens the
+	// value in EditText and Ctrl+O in $EDITOR.
+	Multiline bool
+}
+
+// display is the text shown in the field's input box.
+func (f Field) display(shown bool) string {
+	first, rest, more := strings.Cut(f.Value, "\n")
+	if f.Masked && !shown {
+		first = strings.Repeat("*", len([]rune(first)))
+	}
+	if f.Multiline && more {
+		return fmt.Sprintf("%s … (%d lines)", first, strings.Count(rest, "\n")+2)
+	}
+	return first
 }
 
 // PromptForm renders a simple form and returns values map when submitted or cancelled.
+// Fields are full input lines: Left/Right, Home/End, Ctrl+W and Alt+Backspace
+// (delete word), Ctrl+U (clear) and bracketed paste all work. Tab and Up/Down
+// move between fields.
 func PromptForm(s tcell.Screen, title string, fields []Field) (map[string]string, bool) {
 	styleTitle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
 	styleLabel := tcell.StyleDefault.Foreground(tcell.ColorGreen)
 	styleInput := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
+	styleActive := styleInput.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
 
 	active := 0
+	shown := make([]bool, len(fields))
+	inputs := make([]*inputLine, len(fields))
+	for i := range fields {
+		inputs[i] = newInputLine(fields[i].Value)
+	}
+	// inline reports whether field i is edited in place; multi-line values
+	// are only edited in EditText
+	inline := func(i int) bool {
+		return !fields[i].Multiline || !strings.Contains(fields[i].Value, "\n")
+	}
+	hint := func() string {
+		h := "Enter=Submit  Esc=Cancel  Tab=Next"
+		if fields[active].Multiline {
+			h = "Enter=Edit  Ctrl+O=$EDITOR  Ctrl+S=Submit  Esc=Cancel  Tab=Next"
+		}
+		if fields[active].Masked && fields[active].Revealable {
+			h += "  Ctrl+R=Show/Hide"
+		}
+		return h
+	}
+	submit := func() map[string]string {
+		out := make(map[string]string)
+		for i := range fields {
+			out[fields[i].Label] = fields[i].Value
+		}
+		return out
+	}
+	defer s.HideCursor()
 
 	render := func() {
 		s.Clear()
 		// title
-		for i, r := range title {
+		for i, r := range []rune(title) {
 			s.SetContent(2+i, 1, r, nil, styleTitle)
 		}
 		// fields
+		s.HideCursor()
 		for i := range fields {
 			label := fields[i].Label + ":"
-			for j, r := range label {
+			for j, r := range []rune(label) {
 				s.SetContent(2+j, 3+i*2, r, nil, styleLabel)
 			}
-			// input box
-			val := fields[i].Value
-			if fields[i].Masked {
-				val = strings.Repeat("*", len(val))
+			st := styleInput
+			if i == active {
+				st = styleActive
 			}
-			for j := 0; j < fields[i].Width; j++ {
-				ch := ' '
-				if j < len(val) {
-					ch = rune(val[j])
+			masked := fields[i].Masked && !shown[i]
+			if !inline(i) {
+				val := []rune(fields[i].display(shown[i]))
+				for j := 0; j < fields[i].Width; j++ {
+					ch := ' '
+					if j < len(val) {
+						ch = val[j]
+					}
+					s.SetContent(15+j, 3+i*2, ch, nil, st)
 				}
-				st := styleInput
-				if i == active {
-					st = st.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
-				}
-				s.SetContent(15+j, 3+i*2, ch, nil, st)
+				continue
+			}
+			cx := inputs[i].draw(s, 15, 3+i*2, fields[i].Width, masked, st)
+			if i == active {
+				s.ShowCursor(cx, 3+i*2)
 			}
 		}
 		// footer
-		hint := "Enter=Submit  Esc=Cancel  Tab=Next"
-		for i, r := range hint {
+		for i, r := range hint() {
 			s.SetContent(2+i, 3+len(fields)*2, r, nil, styleLabel)
 		}
 		s.Show()
 	}
 
+	var paste pasteBuffer
+	insertPaste := func(text string) {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+		f := &fields[active]
+		if !f.Multiline {
+			// a trailing newline is common when copying keys and tokens
+			text = strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", " ")
+		}
+		if !inline(active) {
+			f.Value += text
+			return
+		}
+		inputs[active].insert(text)
+		f.Value = inputs[active].value()
+	}
+
 	render()
 
 	for {
 		e := s.PollEvent()
+		if text, ended, took := paste.feed(e); took {
+			if ended {
+				insertPaste(text)
+				render()
+			}
+			continue
+		}
 		switch ev := e.(type) {
+		case *tcell.EventResize:
+			render()
 		case *tcell.EventKey:
-			if ev.Key() == tcell.KeyEsc {
+			switch ev.Key() {
+			case tcell.KeyEsc:
 				return nil, true
-			}
-			if ev.Key() == tcell.KeyTAB || ev.Key() == tcell.KeyRight {
+			case tcell.KeyTAB, tcell.KeyDown:
 				active = (active + 1) % len(fields)
 				render()
 				continue
-			}
-			if ev.Key() == tcell.KeyBacktab || ev.Key() == tcell.KeyLeft {
+			case tcell.KeyBacktab, tcell.KeyUp:
 				active = (active - 1 + len(fields)) % len(fields)
 				render()
 				continue
+			case tcell.KeyCtrlR:
+				if fields[active].Masked && fields[active].Revealable {
+					shown[active] = !shown[active]
+					render()
+				}
+				continue
 			}
-			if ev.Key() == tcell.KeyEnter {
-				// collect values
-				out := make(map[string]string)
-				for i := range fields {
-					out[fields[i].Label] = fields[i].Value
+			f := &fields[active]
+			if f.Multiline && (ev.Key() == tcell.KeyEnter || ev.Key() == tcell.KeyCtrlE) {
+				if v, cancel := EditText(s, f.Label, f.Value, f.Masked && !shown[active]); !cancel {
+					f.Value = v
+					inputs[active].set(v)
 				}
-				return out, false
+				render()
+				continue
 			}
-			if ev.Key() == tcell.KeyBackspace || ev.Key() == tcell.KeyBackspace2 {
-				if len(fields[active].Value) > 0 {
-					fields[active].Value = fields[active].Value[:len(fields[active].Value)-1]
-					render()
+			if f.Multiline && ev.Key() == tcell.KeyCtrlO {
+				v, err := editExternal(s, f.Label, f.Value)
+				if err == nil {
+					f.Value = v
+					inputs[active].set(v)
+				}
+				render()
+				if err != nil {
+					DrawStatus(s, "Cannot edit in $EDITOR: "+err.Error())
 				}
 				continue
 			}
-			if ev.Rune() != 0 {
-				ch := ev.Rune()
-				fields[active].Value = fields[active].Value + string(ch)
+			if ev.Key() == tcell.KeyEnter || ev.Key() == tcell.KeyCtrlS {
+				return submit(), false
+			}
+			if inline(active) && inputs[active].handle(ev) {
+				f.Value = inputs[active].value()
 				render()
 			}
+		case nil:
+			return nil, true
 		}
 	}
 }
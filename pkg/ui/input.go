@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// inputLine is a single-line text input. The text is kept as grapheme
+// clusters so the cursor never splits an accented letter or emoji.
+type inputLine struct {
+	clusters []string
+	cursor   int // insertion point, in clusters
+	offset   int // first visible cluster when the text is wider than the box
+}
+
+func newInputLine(text string) *inputLine {
+	in := &inputLine{}
+	in.set(text)
+	return in
+}
+
+// graphemes splits s into grapheme clusters.
+func graphemes(s string) []string {
+	var out []string
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		out = append(out, g.Str())
+	}
+	return out
+}
+
+// set replaces the text and puts the cursor at the end.
+func (in *inputLine) set(text string) {
+	in.clusters = graphemes(text)
+	in.cursor = len(in.clusters)
+	in.offset = 0
+}
+
+func (in *inputLine) value() string {
+	return strings.Join(in.clusters, "")
+}
+
+// insert adds text at the cursor. The result is segmented again, because a
+// combining mark joins the cluster before it.
+func (in *inputLine) insert(text string) {
+	left := strings.Join(in.clusters[:in.cursor], "") + text
+	right := strings.Join(in.clusters[in.cursor:], "")
+	in.clusters = graphemes(left + right)
+	in.cursor = min(len(graphemes(left)), len(in.clusters))
+}
+
+func (in *inputLine) backspace() {
+	if in.cursor > 0 {
+		in.clusters = append(in.clusters[:in.cursor-1], in.clusters[in.cursor:]...)
+		in.cursor--
+	}
+}
+
+func (in *inputLine) delete() {
+	if in.cursor < len(in.clusters) {
+		in.clusters = append(in.clusters[:in.cursor], in.clusters[in.cursor+1:]...)
+	}
+}
+
+func (in *inputLine) left() {
+	if in.cursor > 0 {
+		in.cursor--
+	}
+}
+
+func (in *inputLine) right() {
+	if in.cursor < len(in.clusters) {
+		in.cursor++
+	}
+}
+
+func (in *inputLine) home() { in.cursor = 0 }
+func (in *inputLine) end()  { in.cursor = len(in.clusters) }
+
+func (in *inputLine) space(i int) bool {
+	return strings.IndexFunc(in.clusters[i], func(r rune) bool { return !unicode.IsSpace(r) }) < 0
+}
+
+// wordStart is the start of the word before the cursor, skipping spaces.
+func (in *inputLine) wordStart() int {
+	i := in.cursor
+	for i > 0 && in.space(i-1) {
+		i--
+	}
+	for i > 0 && !in.space(i-1) {
+		i--
+	}
+	return i
+}
+
+// wordEnd is the end of the word after the cursor, skipping spaces.
+func (in *inputLine) wordEnd() int {
+	i := in.cursor
+	for i < len(in.clusters) && in.space(i) {
+		i++
+	}
+	for i < len(in.clusters) && !in.space(i) {
+		i++
+	}
+	return i
+}
+
+func (in *inputLine) wordLeft()  { in.cursor = in.wordStart() }
+func (in *inputLine) wordRight() { in.cursor = in.wordEnd() }
+
+// deleteWord removes the word before the cursor, like Ctrl+W in a shell.
+func (in *inputLine) deleteWord() {
+	start := in.wordStart()
+	in.clusters = append(in.clusters[:start], in.clusters[in.cursor:]...)
+	in.cursor = start
+}
+
+func (in *inputLine) clear() {
+	in.set("")
+}
+
+// handle applies an editing key and reports whether it was one.
+func (in *inputLine) handle(ev *tcell.EventKey) bool {
+	word := ev.Modifiers()&(tcell.ModCtrl|tcell.ModAlt) != 0
+	switch ev.Key() {
+	case tcell.KeyLeft:
+		if word {
+			in.wordLeft()
+		} else {
+			in.left()
+		}
+	case tcell.KeyRight:
+		if word {
+			in.wordRight()
+		} else {
+			in.right()
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		in.home()
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		in.end()
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if ev.Modifiers()&tcell.ModAlt != 0 {
+			in.deleteWord()
+		} else {
+			in.backspace()
+		}
+	case tcell.KeyDelete:
+		in.delete()
+	case tcell.KeyCtrlW:
+		in.deleteWord()
+	case tcell.KeyCtrlU:
+		in.clear()
+	case tcell.KeyRune:
+		if ev.Modifiers()&tcell.ModAlt != 0 {
+			switch ev.Rune() {
+			case 'b':
+				in.wordLeft()
+			case 'f':
+				in.wordRight()
+			default:
+				return false
+			}
+			return true
+		}
+		in.insert(string(ev.Rune()))
+	default:
+		return false
+	}
+	return true
+}
+
+// cell is what one cluster looks like on screen.
+func (in *inputLine) cell(i int, masked bool) (string, int) {
+	if masked {
+		return "*", 1
+	}
+	c := in.clusters[i]
+	if c == "\t" {
+		return " ", 1
+	}
+	return c, uniseg.StringWidth(c)
+}
+
+// draw renders the input into the width cells at x,y, scrolling so the cursor
+// stays visible. It returns the screen column of the cursor.
+func (in *inputLine) draw(s tcell.Screen, x, y, width int, masked bool, style tcell.Style) int {
+	if in.cursor < in.offset {
+		in.offset = in.cursor
+	}
+	// keep one cell free for the cursor at the end of the text
+	for in.offset < in.cursor {
+		w := 1
+		for i := in.offset; i < in.cursor; i++ {
+			_, cw := in.cell(i, masked)
+			w += cw
+		}
+		if w <= width {
+			break
+		}
+		in.offset++
+	}
+
+	for j := 0; j < width; j++ {
+		s.SetContent(x+j, y, ' ', nil, style)
+	}
+	col, cursorX := 0, 0
+	for i := in.offset; i < len(in.clusters); i++ {
+		if i == in.cursor {
+			cursorX = col
+		}
+		c, cw := in.cell(i, masked)
+		if col+cw > width {
+			s.SetContent(x+width-1, y, '»', nil, style)
+			break
+		}
+		rs := []rune(c)
+		s.SetContent(x+col, y, rs[0], rs[1:], style)
+		col += cw
+	}
+	if in.cursor == len(in.clusters) {
+		cursorX = col
+	}
+	if in.offset > 0 {
+		s.SetContent(x-1, y, '«', nil, tcell.StyleDefault)
+	}
+	return x + min(cursorX, width-1)
+}
+
+// pasteBuffer collects a bracketed paste. The text arrives key by key between
+// the paste start and end events, so it is held back until the end and then
+// inserted in one go.
+type pasteBuffer struct {
+	text   strings.Builder
+	active bool
+}
+
+// feed takes ev if it is part of a paste and reports whether it did. When ev
+// ends the paste, ended is set and text holds everything pasted.
+func (p *pasteBuffer) feed(ev tcell.Event) (text string, ended, took bool) {
+	switch ev := ev.(type) {
+	case *tcell.EventPaste:
+		if ev.Start() {
+			p.text.Reset()
+			p.active = true
+			return "", false, true
+		}
+		if !p.active {
+			return "", false, true
+		}
+		p.active = false
+		text = p.text.String()
+		p.text.Reset()
+		return text, true, true
+	case *tcell.EventKey:
+		if !p.active {
+			return "", false, false
+		}
+		switch ev.Key() {
+		case tcell.KeyRune:
+			p.text.WriteRune(ev.Rune())
+		case tcell.KeyEnter:
+			p.text.WriteByte('\r')
+		case tcell.KeyLF:
+			p.text.WriteByte('\n')
+		case tcell.KeyTab:
+			p.text.WriteByte('\t')
+		}
+		return "", false, true
+	}
+	return "", false, false
+}
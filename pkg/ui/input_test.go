@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func key(k tcell.Key, mod tcell.ModMask) *tcell.EventKey {
+	return tcell.NewEventKey(k, 0, mod)
+}
+
+func runes(s string) []*tcell.EventKey {
+	var evs []*tcell.EventKey
+	for _, r := range s {
+		evs = append(evs, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	return evs
+}
+
+func TestInputLineEditing(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  string
+		keys   []*tcell.EventKey
+		want   string
+		cursor int
+	}{
+		{"type at end", "ab", runes("cd"), "abcd", 4},
+		{"insert after home", "world", append([]*tcell.EventKey{key(tcell.KeyHome, 0)}, runes("hi ")...), "hi world", 3},
+		{"backspace", "abc", []*tcell.EventKey{key(tcell.KeyBackspace2, 0)}, "ab", 2},
+		{"backspace at start", "abc", []*tcell.EventKey{key(tcell.KeyCtrlA, 0), key(tcell.KeyBackspace2, 0)}, "abc", 0},
+		{"delete", "abc", []*tcell.EventKey{key(tcell.KeyHome, 0), key(tcell.KeyDelete, 0)}, "bc", 0},
+		{"ctrl+w", "one two  ", []*tcell.EventKey{key(tcell.KeyCtrlW, 0)}, "one ", 4},
+		{"alt+backspace", "one two", []*tcell.EventKey{key(tcell.KeyBackspace2, tcell.ModAlt)}, "one ", 4},
+		{"ctrl+u", "secret", []*tcell.EventKey{key(tcell.KeyCtrlU, 0)}, "", 0},
+		{"word left", "one two three", []*tcell.EventKey{key(tcell.KeyLeft, tcell.ModCtrl), key(tcell.KeyLeft, tcell.ModCtrl)}, "one two three", 4},
+		{"word right", "one two", []*tcell.EventKey{key(tcell.KeyHome, 0), key(tcell.KeyRight, tcell.ModCtrl)}, "one two", 3},
+		{"alt+b", "one two", []*tcell.EventKey{tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModAlt)}, "one two", 4},
+		{"end", "abc", []*tcell.EventKey{key(tcell.KeyHome, 0), key(tcell.KeyCtrlE, 0)}, "abc", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := newInputLine(tt.start)
+			for _, ev := range tt.keys {
+				if !in.handle(ev) {
+					t.Fatalf("key %v not handled", ev.Name())
+				}
+			}
+			if in.value() != tt.want || in.cursor != tt.cursor {
+				t.Errorf("got %q cursor %d, want %q cursor %d", in.value(), in.cursor, tt.want, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestInputLineGraphemes(t *testing.T) {
+	// "e" + combining acute, a flag and a ZWJ family are one cluster each
+	in := newInputLine("e\u0301\U0001F1E9\U0001F1EA\U0001F468\u200d\U0001F469\u200d\U0001F467")
+	if len(in.clusters) != 3 {
+		t.Fatalf("got %d clusters %q, want 3", len(in.clusters), in.clusters)
+	}
+	in.left()
+	in.backspace()
+	if in.value() != "e\u0301\U0001F468\u200d\U0001F469\u200d\U0001F467" || in.cursor != 1 {
+		t.Errorf("backspace split a cluster: %q cursor %d", in.value(), in.cursor)
+	}
+
+	// a combining mark typed after a letter joins its cluster
+	in = newInputLine("cafe")
+	in.insert("\u0301")
+	if len(in.clusters) != 4 || in.cursor != 4 {
+		t.Errorf("got clusters %q cursor %d, want 4 clusters", in.clusters, in.cursor)
+	}
+}
+
+func TestInputLineDrawScrolls(t *testing.T) {
+	s := tcell.NewSimulationScreen("")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Fini()
+	s.SetSize(20, 1)
+
+	in := newInputLine("0123456789abcdef")
+	cx := in.draw(s, 1, 0, 8, false, tcell.StyleDefault)
+	if cx != 8 || in.offset != 9 {
+		t.Errorf("cursor x %d, offset %d; want 8, 9", cx, in.offset)
+	}
+	in.home()
+	cx = in.draw(s, 1, 0, 8, false, tcell.StyleDefault)
+	if cx != 1 || in.offset != 0 {
+		t.Errorf("after home: cursor x %d, offset %d; want 1, 0", cx, in.offset)
+	}
+	if r, _, _, _ := s.GetContent(8, 0); r != '»' {
+		t.Errorf("overflow marker %q, want »", r)
+	}
+}
+
+func TestInputLineMaskedCells(t *testing.T) {
+	in := newInputLine("日本")
+	if c, w := in.cell(0, false); c != "日" || w != 2 {
+		t.Errorf("cell(0) = %q, %d; want 日, 2", c, w)
+	}
+	if c, w := in.cell(0, true); c != "*" || w != 1 {
+		t.Errorf("masked cell(0) = %q, %d; want *, 1", c, w)
+	}
+}
+
+func TestPasteBuffer(t *testing.T) {
+	var p pasteBuffer
+	if _, _, took := p.feed(runes("a")[0]); took {
+		t.Error("took a key outside a paste")
+	}
+	if _, _, took := p.feed(tcell.NewEventPaste(false)); !took {
+		t.Error("did not take a stray paste end")
+	}
+	p.feed(tcell.NewEventPaste(true))
+	evs := append(runes("x"), key(tcell.KeyEnter, 0), key(tcell.KeyTab, 0), key(tcell.KeyLF, 0), key(tcell.KeyEsc, 0))
+	for _, ev := range evs {
+		if text, ended, took := p.feed(ev); !took || ended || text != "" {
+			t.Fatalf("key in paste: %q, ended %v, took %v", text, ended, took)
+		}
+	}
+	if text, ended, took := p.feed(tcell.NewEventPaste(false)); text != "x\r\t\n" || !ended || !took {
+		t.Errorf("end: %q, ended %v, took %v", text, ended, took)
+	}
+	if _, _, took := p.feed(runes("a")[0]); took {
+		t.Error("took a key after the paste ended")
+	}
+}
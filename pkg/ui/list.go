@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -18,3 +20,82 @@ func DrawList(s tcell.Screen, items []string, selected int) {
 	}
 	s.Show()
 }
+
+// listView is a scrollable list of pre-formatted rows with a cursor. It only
+// tracks state; screens decide what the rows mean.
+type listView struct {
+	rows   []string
+	cursor int
+	offset int // first visible row
+}
+
+// setRows replaces the rows, keeping the cursor in range.
+func (l *listView) setRows(rows []string) {
+	l.rows = rows
+	l.move(0)
+}
+
+// move shifts the cursor by delta rows, clamped to the list.
+func (l *listView) move(delta int) {
+	l.cursor += delta
+	if l.cursor >= len(l.rows) {
+		l.cursor = len(l.rows) - 1
+	}
+	if l.cursor < 0 {
+		l.cursor = 0
+	}
+}
+
+// draw renders the visible window of rows into the box at x,y of size w,h,
+// scrolling so the cursor stays visible.
+func (l *listView) draw(s tcell.Screen, x, y, w, h int) {
+	if h <= 0 {
+		return
+	}
+	if l.cursor < l.offset {
+		l.offset = l.cursor
+	}
+	if l.cursor >= l.offset+h {
+		l.offset = l.cursor - h + 1
+	}
+	style := tcell.StyleDefault
+	sel := tcell.StyleDefault.Reverse(true)
+	for i := 0; i < h; i++ {
+		idx := l.offset + i
+		st := style
+		if idx == l.cursor && len(l.rows) > 0 {
+			st = sel
+		}
+		row := ""
+		if idx < len(l.rows) {
+			row = l.rows[idx]
+		}
+		rs := []rune(row)
+		for j := 0; j < w; j++ {
+			ch := ' '
+			if j < len(rs) {
+				ch = rs[j]
+			}
+			s.SetContent(x+j, y+i, ch, nil, st)
+		}
+	}
+	// scroll markers
+	if l.offset > 0 {
+		s.SetContent(x+w-1, y, '↑', nil, style)
+	}
+	if l.offset+h < len(l.rows) {
+		s.SetContent(x+w-1, y+h-1, '↓', nil, style)
+	}
+}
+
+// fit pads or truncates str to exactly n runes.
+func fit(str string, n int) string {
+	rs := []rune(str)
+	if len(rs) > n {
+		if n > 1 {
+			return string(rs[:n-1]) + "…"
+		}
+		return string(rs[:n])
+	}
+	return str + strings.Repeat(" ", n-len(rs))
+}
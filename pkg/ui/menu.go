@@ -16,3 +16,103 @@ func DrawMenu(s tcell.Screen, items []string, selected int) {
 	}
 	s.Show()
 }
+
+// Choose shows title and a vertical list of options and returns the index the
+// user picked with Enter, or cancelled=true on Esc.
+func Choose(s tcell.Screen, title string, options []string) (int, bool) {
+	styleTitle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+	styleHint := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	selected := 0
+
+	render := func() {
+		s.Clear()
+		for i, r := range title {
+			s.SetContent(2+i, 1, r, nil, styleTitle)
+		}
+		for i, opt := range options {
+			st := tcell.StyleDefault
+			prefix := "  "
+			if i == selected {
+				st = st.Reverse(true)
+				prefix = "▶ "
+			}
+			for j, r := range []rune(prefix + opt) {
+				s.SetContent(4+j, 3+i, r, nil, st)
+			}
+		}
+		hint := "↑↓=Move  Enter=Select  Esc=Cancel"
+		for i, r := range hint {
+			s.SetContent(2+i, 4+len(options), r, nil, styleHint)
+		}
+		s.Show()
+	}
+
+	render()
+	for {
+		switch ev := s.PollEvent().(type) {
+		case *tcell.EventResize:
+			render()
+		case *tcell.EventKey:
+			switch ev.Key() {
+			case tcell.KeyEscape:
+				return 0, true
+			case tcell.KeyEnter:
+				return selected, false
+			case tcell.KeyUp:
+				selected = (selected - 1 + len(options)) % len(options)
+			case tcell.KeyDown, tcell.KeyTAB:
+				selected = (selected + 1) % len(options)
+			case tcell.KeyRune:
+				switch ev.Rune() {
+				case 'k':
+					selected = (selected - 1 + len(options)) % len(options)
+				case 'j':
+					selected = (selected + 1) % len(options)
+				}
+			}
+			render()
+		case nil:
+			return 0, true
+		}
+	}
+}
+
+// Confirm shows title and lines and waits for y (true) or n/Esc (false).
+func Confirm(s tcell.Screen, title string, lines []string) bool {
+	styleTitle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+	styleHint := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+
+	render := func() {
+		s.Clear()
+		for i, r := range title {
+			s.SetContent(2+i, 1, r, nil, styleTitle)
+		}
+		for i, line := range lines {
+			for j, r := range []rune(line) {
+				s.SetContent(4+j, 3+i, r, nil, tcell.StyleDefault)
+			}
+		}
+		hint := "y=Yes  n/Esc=No"
+		for i, r := range hint {
+			s.SetContent(2+i, 4+len(lines), r, nil, styleHint)
+		}
+		s.Show()
+	}
+
+	render()
+	for {
+		switch ev := s.PollEvent().(type) {
+		case *tcell.EventResize:
+			render()
+		case *tcell.EventKey:
+			switch {
+			case ev.Key() == tcell.KeyEscape, ev.Rune() == 'n', ev.Rune() == 'N':
+				return false
+			case ev.Rune() == 'y', ev.Rune() == 'Y':
+				return true
+			}
+		case nil:
+			return false
+		}
+	}
+}
@@ -1,129 +1,239 @@
 package ui
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"strconv"
+	"net/http"
+	"strings"
+	"time"
 
 	"sm-cli/pkg/api"
+	"sm-cli/pkg/credstore"
 )
 
+// ShowLogin lets the user pick a login method and runs it. The caller
+// re-renders the main menu afterwards, which shows the outcome.
 func (u *UI) ShowLogin() {
-	// Prompt for API key only
+	choice, cancel := Choose(u.s, "Login", []string{"Email + password", "API key"})
+	if cancel {
+		return
+	}
+	if choice == 0 {
+		u.showPasswordLogin()
+	} else {
+		u.showAPIKeyLogin()
+	}
+}
+
+func (u *UI) showAPIKeyLogin() {
 	fields := []Field{{Label: "API Key", Width: 68}}
 	vals, cancel := PromptForm(u.s, "Login with API Key", fields)
 	if cancel {
-		u.ShowMainMenu()
 		return
 	}
 
 	key := vals["API Key"]
 	if key == "" {
-		DrawStatus(u.s, "API key required")
-		u.ShowMainMenu()
+		u.setStatus("API key required")
 		return
 	}
 
-	email, err := api.ValidateAPIKey(key)
+	var email string
+	err := u.runCancellable("Validating API key...", func(ctx context.Context) error {
+		var err error
+		email, err = api.Default().ValidateAPIKey(ctx, key)
+		return err
+	})
 	if err != nil {
-		DrawStatus(u.s, fmt.Sprintf("Login failed: %v", err))
-		u.ShowMainMenu()
+		u.setStatus("Login failed: " + loginErrorText(err))
 		return
 	}
 
-	DrawStatus(u.s, "Login successful: "+email)
-	// return to main menu where email will be shown
-	u.ShowMainMenu()
+	u.saveLogin(key)
+	u.setStatus("Login successful: " + email)
 }
 
-func (u *UI) ShowSignup() {
+func (u *UI) showPasswordLogin() {
 	fields := []Field{{Label: "Email", Width: 40}, {Label: "Password", Width: 40, Masked: true}, {Label: "MasterPassword", Width: 40, Masked: true}}
-	vals, cancel := PromptForm(u.s, "Signup", fields)
+	vals, cancel := PromptForm(u.s, "Login with email and password", fields)
 	if cancel {
-		u.ShowMainMenu()
 		return
 	}
-
-	resp, err := api.Signup(vals["Email"], vals["Password"], vals["MasterPassword"])
-	if err != nil {
-		DrawStatus(u.s, fmt.Sprintf("Signup failed: %v", err))
-		u.ShowMainMenu()
+	if vals["Email"] == "" || vals["Password"] == "" || vals["MasterPassword"] == "" {
+		u.setStatus("Email, password and master password are required")
 		return
 	}
-	defer resp.Body.Close()
-	b, _ := ioutil.ReadAll(resp.Body)
-	if resp.StatusCode != 201 {
-		DrawStatus(u.s, fmt.Sprintf("Signup failed: %s", string(b)))
-		u.ShowMainMenu()
+
+	var auth *api.AuthResponse
+	err := u.runCancellable("Logging in...", func(ctx context.Context) error {
+		var err error
+		auth, err = api.Default().Login(ctx, vals["Email"], vals["Password"], vals["MasterPassword"])
+		return err
+	})
+	if err != nil {
+		u.setStatus("Login failed: " + loginErrorText(err))
 		return
 	}
-	var out map[string]interface{}
-	json.Unmarshal(b, &out)
-	if token, ok := out["token"].(string); ok {
-		api.SetToken(token)
-		DrawStatus(u.s, "Signup successful")
-		u.ShowSecretsList(1)
-		return
+
+	api.SetToken(auth.Token)
+	u.saveLogin(auth.Token)
+
+	email := vals["Email"]
+	msg := "Login successful: " + email
+	if claims, err := api.ParseToken(auth.Token); err == nil {
+		if claims.Email != "" {
+			email = claims.Email
+		}
+		msg = "Login successful: " + email
+		if !claims.ExpiresAt.IsZero() {
+			msg += ", session valid until " + claims.ExpiresAt.Local().Format("Jan 2 15:04")
+		}
 	}
-	DrawStatus(u.s, "Signup failed: invalid server response")
-	u.ShowMainMenu()
+	u.setStatus(msg)
 }
 
-func (u *UI) ShowSecretsList(page int) {
-	resp, err := api.GetSecrets(page, 20)
+// ShowSignup creates an account and, on success, logs in and opens the
+// secrets list. Invalid input re-opens the form with the values kept.
+func (u *UI) ShowSignup() {
+	fields := []Field{
+		{Label: "Email", Width: 40},
+		{Label: "Password", Width: 40, Masked: true},
+		{Label: "Confirm", Width: 40, Masked: true},
+		{Label: "MasterPassword", Width: 40, Masked: true},
+		{Label: "ConfirmMaster", Width: 40, Masked: true},
+	}
+	title := "Signup"
+	var vals map[string]string
+	for {
+		var cancel bool
+		vals, cancel = PromptForm(u.s, title, fields)
+		if cancel {
+			return
+		}
+		err := signupError(vals)
+		if err == nil {
+			break
+		}
+		title = "Signup - " + err.Error()
+	}
+
+	var auth *api.AuthResponse
+	err := u.runCancellable("Creating account...", func(ctx context.Context) error {
+		var err error
+		auth, err = api.Default().Signup(ctx, vals["Email"], vals["Password"], vals["MasterPassword"])
+		return err
+	})
 	if err != nil {
-		DrawStatus(u.s, fmt.Sprintf("Failed to load secrets: %v", err))
+		u.setStatus("Signup failed: " + errorText(err))
 		return
 	}
-	defer resp.Body.Close()
-	b, _ := ioutil.ReadAll(resp.Body)
-	if resp.StatusCode != 200 {
-		DrawStatus(u.s, fmt.Sprintf("Failed to load secrets: %s", string(b)))
-		return
+	api.SetToken(auth.Token)
+	u.saveLogin(auth.Token)
+	DrawStatus(u.s, "Signup successful, welcome "+vals["Email"])
+	u.ShowSecretsList(1)
+}
+
+// signupError validates the signup form.
+func signupError(vals map[string]string) error {
+	if err := validateEmail(vals["Email"]); err != nil {
+		return err
+	}
+	if err := validatePassword(vals["Password"]); err != nil {
+		return err
+	}
+	if vals["Password"] != vals["Confirm"] {
+		return errors.New("passwords do not match")
+	}
+	if err := validateMasterPassword(vals["MasterPassword"], vals["Password"]); err != nil {
+		return err
+	}
+	if vals["MasterPassword"] != vals["ConfirmMaster"] {
+		return errors.New("master passwords do not match")
 	}
-	var out map[string]interface{}
-	json.Unmarshal(b, &out)
-	items := []string{}
-	if arr, ok := out["secrets"].([]interface{}); ok {
-		for i, v := range arr {
-			if m, ok := v.(map[string]interface{}); ok {
-				items = append(items, strconv.Itoa(i+1)+". "+fmt.Sprintf("%v", m["name"]))
+	return nil
+}
+
+// errorText turns an api error into a short message for the status bar.
+func errorText(err error) string {
+	var apiErr *api.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, api.ErrUnauthorized):
+		return "session expired or credentials invalid, please log in again"
+	case errors.Is(err, api.ErrNotFound):
+		return "not found"
+	case errors.Is(err, api.ErrConflict):
+		return "already exists"
+	case errors.Is(err, api.ErrRateLimited):
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			return fmt.Sprintf("rate limited, retry in %s", apiErr.RetryAfter.Round(time.Second))
+		}
+		return "rate limited, try again shortly"
+	case errors.As(err, &apiErr) && apiErr.Message != "":
+		return apiErr.Message
+	}
+	return err.Error()
+}
+
+// loginErrorText explains why a login attempt was rejected.
+func loginErrorText(err error) string {
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusLocked || strings.Contains(strings.ToLower(apiErr.Code+apiErr.Message), "locked"):
+			if apiErr.Message != "" {
+				return "account locked: " + apiErr.Message
 			}
+			return "account locked"
+		case apiErr.StatusCode == http.StatusUnauthorized:
+			if apiErr.Message != "" {
+				return apiErr.Message
+			}
+			return "wrong email, password or master password"
+		case apiErr.StatusCode == http.StatusForbidden:
+			return "access denied: " + apiErr.Message
 		}
 	}
-	if len(items) == 0 {
-		items = append(items, "(no secrets)")
+	return errorText(err)
+}
+
+// handleAuthError drops the stored token when the backend rejected it, so the
+// menu falls back to the logged-out state.
+func (u *UI) handleAuthError(err error) {
+	if errors.Is(err, api.ErrUnauthorized) {
+		api.SetToken("")
 	}
-	DrawList(u.s, items, 0)
-	DrawStatus(u.s, "Loaded secrets")
 }
 
-func (u *UI) ShowAPIKeys(page int) {
-	resp, err := api.GetAPIKeys(page, 20, "")
-	if err != nil {
-		DrawStatus(u.s, fmt.Sprintf("Failed to load apikeys: %v", err))
+// saveLogin remembers token for the active profile so the next launch starts
+// logged in. Failures only cost convenience, so they are reported and ignored.
+func (u *UI) saveLogin(token string) {
+	if u.store == nil {
 		return
 	}
-	defer resp.Body.Close()
-	b, _ := ioutil.ReadAll(resp.Body)
-	if resp.StatusCode != 200 {
-		DrawStatus(u.s, fmt.Sprintf("Failed to load apikeys: %s", string(b)))
-		return
+	if err := u.store.Set(u.profile, token); err != nil {
+		DrawStatus(u.s, "Could not save login: "+err.Error())
 	}
-	var out map[string]interface{}
-	json.Unmarshal(b, &out)
-	items := []string{}
-	if arr, ok := out["api_keys"].([]interface{}); ok {
-		for i, v := range arr {
-			if m, ok := v.(map[string]interface{}); ok {
-				items = append(items, strconv.Itoa(i+1)+". "+fmt.Sprintf("%v", m["name"]))
-			}
-		}
+}
+
+// PromptPassphrase asks for the credentials file passphrase inside the TUI.
+// A new credentials file gets the passphrase twice, so a typo cannot lock the
+// user out of it.
+func (u *UI) PromptPassphrase() (string, error) {
+	fields := []Field{{Label: "Passphrase", Width: 40, Masked: true}}
+	fs, ok := u.store.(*credstore.FileStore)
+	creating := ok && !fs.Exists()
+	if creating {
+		fields = append(fields, Field{Label: "Confirm", Width: 40, Masked: true})
+	}
+	vals, cancel := PromptForm(u.s, "Passphrase for saved credentials", fields)
+	if cancel {
+		return "", errors.New("passphrase entry cancelled")
 	}
-	if len(items) == 0 {
-		items = append(items, "(no api keys)")
+	if creating && vals["Passphrase"] != vals["Confirm"] {
+		return "", errors.New("passphrases do not match")
 	}
-	DrawList(u.s, items, 0)
-	DrawStatus(u.s, "Loaded api keys")
+	return vals["Passphrase"], nil
 }
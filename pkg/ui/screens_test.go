@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"sm-cli/pkg/credstore"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// typePassphrase types the keys for a passphrase form: each value in its
+// field, then Enter. The event queue is small, so run it alongside the form.
+func typePassphrase(s tcell.SimulationScreen, vals ...string) {
+	for i, v := range vals {
+		if i > 0 {
+			s.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		}
+		for _, r := range v {
+			s.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		}
+	}
+	s.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+}
+
+func TestPromptPassphraseConfirmsNewFile(t *testing.T) {
+	s := tcell.NewSimulationScreen("")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Fini()
+	u := New(s)
+	u.SetCredentialStore(credstore.NewFileStore(filepath.Join(t.TempDir(), "credentials.enc"), nil))
+
+	go typePassphrase(s, "secret", "secret")
+	if got, err := u.PromptPassphrase(); err != nil || got != "secret" {
+		t.Errorf("matching: got %q, %v", got, err)
+	}
+	go typePassphrase(s, "secret", "secrte")
+	if _, err := u.PromptPassphrase(); err == nil {
+		t.Error("mismatched passphrases were accepted")
+	}
+}
+
+func TestPromptPassphraseExistingFileAsksOnce(t *testing.T) {
+	s := tcell.NewSimulationScreen("")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Fini()
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	if err := credstore.NewFileStore(path, func() (string, error) { return "secret", nil }).Set("default", "tok"); err != nil {
+		t.Fatal(err)
+	}
+	u := New(s)
+	u.SetCredentialStore(credstore.NewFileStore(path, nil))
+
+	go typePassphrase(s, "secret")
+	if got, err := u.PromptPassphrase(); err != nil || got != "secret" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
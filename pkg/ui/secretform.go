@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"context"
+
+	"sm-cli/pkg/api"
+)
+
+// secretFields builds the new/edit secret form, pre-filled from sec.
+func secretFields(sec *api.Secret) []Field {
+	return []Field{
+		{Label: "Name", Value: sec.Name, Width: 40},
+		{Label: "Value", Value: sec.Value, Width: 40, Masked: true, Revealable: true, Multiline: true},
+		{Label: "Category", Value: sec.Category, Width: 40},
+		{Label: "Description", Value: sec.Description, Width: 40},
+	}
+}
+
+// promptSecret shows the secret form until it validates, keeping the user's
+// input between attempts. It returns nil when cancelled.
+func (u *UI) promptSecret(title string, sec *api.Secret, problem string) *api.Secret {
+	fields := secretFields(sec)
+	for {
+		t := title
+		if problem != "" {
+			t += " - " + problem
+		}
+		vals, cancel := PromptForm(u.s, t, fields)
+		if cancel {
+			return nil
+		}
+		out := &api.Secret{
+			ID:          sec.ID,
+			Name:        vals["Name"],
+			Value:       vals["Value"],
+			Category:    vals["Category"],
+			Description: vals["Description"],
+		}
+		if err := validateSecret(out.Name, out.Value, out.Category, out.Description); err != nil {
+			problem = err.Error()
+			fields = secretFields(out)
+			continue
+		}
+		return out
+	}
+}
+
+// promptMaster asks for the master password used to encrypt or decrypt name.
+func (u *UI) promptMaster(name string) (string, bool) {
+	fields := []Field{{Label: "Master", Width: 40, Masked: true}}
+	vals, cancel := PromptForm(u.s, "Master password for "+name, fields)
+	if cancel || vals["Master"] == "" {
+		return "", false
+	}
+	return vals["Master"], true
+}
+
+// ShowNewSecret runs the "New secret" form and returns the created secret, or
+// nil when the user gave up. Conflicts send the user back to the form.
+func (u *UI) ShowNewSecret() *api.Secret {
+	draft := &api.Secret{Category: u.defaultCategory}
+	problem := ""
+	// one key per draft, so a retried create cannot store the secret twice
+	key := api.NewIdempotencyKey()
+	for {
+		next := u.promptSecret("New secret", draft, problem)
+		if next == nil {
+			return nil
+		}
+		if *next != *draft {
+			key = api.NewIdempotencyKey()
+		}
+		draft = next
+		master, ok := u.promptMaster(draft.Name)
+		if !ok {
+			return nil
+		}
+		var created *api.Secret
+		err := u.runAuthed("Saving secret...", func(ctx context.Context) error {
+			var err error
+			created, err = api.Default().CreateSecret(api.WithIdempotencyKey(ctx, key), draft.Name, draft.Value, draft.Category, draft.Description, master)
+			return err
+		})
+		if err == nil {
+			u.setStatus("Created secret " + created.Name)
+			return created
+		}
+		u.handleAuthError(err)
+		problem = errorText(err)
+	}
+}
+
+// ShowEditSecret decrypts sec, lets the user edit it and, after confirming
+// the changes, saves it with UpdateSecret. It returns the updated secret, or
+// nil when nothing was saved.
+func (u *UI) ShowEditSecret(sec *api.Secret) *api.Secret {
+	cur, master, ok := u.decryptSecret(sec)
+	if !ok {
+		return nil
+	}
+	draft, problem := cur, ""
+	for {
+		next := u.promptSecret("Edit secret", draft, problem)
+		if next == nil {
+			return nil
+		}
+		draft = next
+		changes := secretDiff(cur, draft)
+		if len(changes) == 0 {
+			u.setStatus("No changes to " + cur.Name)
+			return nil
+		}
+		if !Confirm(u.s, "Save changes to "+cur.Name+"?", changes) {
+			continue
+		}
+		updated, err := u.updateSecret(cur.ID, draft, master)
+		if err == nil {
+			return updated
+		}
+		problem = errorText(err)
+	}
+}
+
+// ShowEditSecretExternal opens the value of sec in $EDITOR and saves it after
+// confirmation. It returns the updated secret, or nil when nothing was saved.
+func (u *UI) ShowEditSecretExternal(sec *api.Secret) *api.Secret {
+	cur, master, ok := u.decryptSecret(sec)
+	if !ok {
+		return nil
+	}
+	value, err := editExternal(u.s, cur.Name, cur.Value)
+	if err != nil {
+		u.setStatus("Cannot edit in $EDITOR: " + err.Error())
+		return nil
+	}
+	draft := *cur
+	draft.Value = value
+	changes := secretDiff(cur, &draft)
+	if len(changes) == 0 {
+		u.setStatus("No changes to " + cur.Name)
+		return nil
+	}
+	if !Confirm(u.s, "Save changes to "+cur.Name+"?", changes) {
+		u.setStatus("Changes to " + cur.Name + " discarded")
+		return nil
+	}
+	updated, err := u.updateSecret(cur.ID, &draft, master)
+	if err != nil {
+		u.setStatus("Failed to save " + cur.Name + ": " + errorText(err))
+		return nil
+	}
+	return updated
+}
+
+// decryptSecret prompts for the master password and fetches sec with its
+// value. Failures are queued for the status bar.
+func (u *UI) decryptSecret(sec *api.Secret) (*api.Secret, string, bool) {
+	master, ok := u.promptMaster(sec.Name)
+	if !ok {
+		return nil, "", false
+	}
+	var cur *api.Secret
+	err := u.runAuthed("Decrypting...", func(ctx context.Context) error {
+		var err error
+		cur, err = api.Default().GetSecret(ctx, sec.ID, master)
+		return err
+	})
+	if err != nil {
+		u.handleAuthError(err)
+		u.setStatus("Cannot decrypt: " + errorText(err))
+		return nil, "", false
+	}
+	return cur, master, true
+}
+
+// updateSecret saves draft over the secret id.
+func (u *UI) updateSecret(id string, draft *api.Secret, master string) (*api.Secret, error) {
+	var updated *api.Secret
+	err := u.runAuthed("Saving secret...", func(ctx context.Context) error {
+		var err error
+		updated, err = api.Default().UpdateSecret(ctx, id, draft.Name, draft.Value, draft.Category, draft.Description, master)
+		return err
+	})
+	if err != nil {
+		u.handleAuthError(err)
+		return nil, err
+	}
+	u.setStatus("Saved secret " + updated.Name)
+	return updated, nil
+}
+
+// secretDiff describes what saving next over cur would change. The value
+// itself is never shown.
+func secretDiff(cur, next *api.Secret) []string {
+	var lines []string
+	field := func(label, from, to string) {
+		if from != to {
+			lines = append(lines, label+": "+quoteOrNone(from)+" → "+quoteOrNone(to))
+		}
+	}
+	field("Name", cur.Name, next.Name)
+	if cur.Value != next.Value {
+		lines = append(lines, "Value: changed")
+	}
+	field("Category", cur.Category, next.Category)
+	field("Description", cur.Description, next.Description)
+	return lines
+}
+
+func quoteOrNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return `"` + s + `"`
+}
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"sm-cli/pkg/api"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const secretsPageSize = 20
+
+// deleteUndoWindow is how long a confirmed delete can still be undone before
+// the request is sent.
+const deleteUndoWindow = 5 * time.Second
+
+// secretsBrowser is the state of the interactive secrets list.
+type secretsBrowser struct {
+	u    *UI
+	list *api.SecretList
+	view listView
+}
+
+// ShowSecretsList opens the secrets browser at page and returns when the
+// user leaves it.
+func (u *UI) ShowSecretsList(page int) {
+	b := &secretsBrowser{u: u}
+	if !b.load(page) {
+		return
+	}
+	b.run()
+}
+
+// load fetches page and reports whether it succeeded. Failures are shown in
+// the status bar; the previous page, if any, stays on screen.
+func (b *secretsBrowser) load(page int) bool {
+	var list *api.SecretList
+	err := b.u.runAuthed("Loading secrets...", func(ctx context.Context) error {
+		var err error
+		list, err = api.Default().GetSecrets(ctx, page, secretsPageSize)
+		return err
+	})
+	if err != nil {
+		b.u.handleAuthError(err)
+		// shown by the browser, or by the main menu if this was the first page
+		b.u.setStatus("Failed to load secrets: " + errorText(err))
+		return false
+	}
+	b.list = list
+	b.view.cursor, b.view.offset = 0, 0
+	b.view.setRows(b.rows())
+	return true
+}
+
+// reload refreshes the current page after a change, keeping the cursor.
+func (b *secretsBrowser) reload() {
+	cursor := b.view.cursor
+	if b.load(b.list.Page.Page) {
+		b.view.move(cursor)
+	}
+}
+
+// delete removes the selected secret once the user has typed its name and
+// let the undo window run out. The outcome is queued for the status bar.
+func (b *secretsBrowser) delete() {
+	sec := b.selected()
+	if sec == nil {
+		return
+	}
+	fields := []Field{{Label: "Name", Width: 40}}
+	vals, cancel := PromptForm(b.u.s, "Delete "+sec.Name+"? Type its name to confirm", fields)
+	b.render()
+	if cancel {
+		return
+	}
+	if vals["Name"] != sec.Name {
+		b.u.setStatus("Name did not match, " + sec.Name + " was not deleted")
+		return
+	}
+	if !b.u.waitUndo(deleteUndoWindow, func(left time.Duration) string {
+		return fmt.Sprintf("Deleting %s in %s - press u to undo", sec.Name, left)
+	}) {
+		b.u.setStatus("Delete of " + sec.Name + " undone")
+		return
+	}
+	err := b.u.runAuthed("Deleting "+sec.Name+"...", func(ctx context.Context) error {
+		return api.Default().DeleteSecret(ctx, sec.ID)
+	})
+	if err != nil {
+		b.u.handleAuthError(err)
+		b.u.setStatus("Failed to delete " + sec.Name + ": " + errorText(err))
+		return
+	}
+	name := sec.Name
+	page := b.list.Page.Page
+	if len(b.list.Secrets) == 1 && page > 1 {
+		// the page is now empty, step back
+		page--
+	}
+	cursor := b.view.cursor
+	if b.load(page) {
+		b.view.move(cursor)
+		b.u.setStatus("Deleted " + name)
+	}
+}
+
+func (b *secretsBrowser) rows() []string {
+	rows := make([]string, 0, len(b.list.Secrets))
+	for i, sec := range b.list.Secrets {
+		n := (b.list.Page.Page-1)*b.list.Limit + i + 1
+		rows = append(rows, fmt.Sprintf(" %s %s %s %s",
+			fit(strconv.Itoa(n)+".", 5), fit(sec.Name, 32), fit(sec.Category, 16), formatDate(sec.UpdatedAt)))
+	}
+	return rows
+}
+
+// selected returns the secret under the cursor, or nil on an empty page.
+func (b *secretsBrowser) selected() *api.Secret {
+	if len(b.list.Secrets) == 0 {
+		return nil
+	}
+	return &b.list.Secrets[b.view.cursor]
+}
+
+func (b *secretsBrowser) render() {
+	s := b.u.s
+	s.Clear()
+	w, h := s.Size()
+	styleTitle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+	styleHeader := tcell.StyleDefault.Bold(true)
+	styleHint := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+
+	b.u.drawText(2, 1, " Secrets ", styleTitle)
+	b.u.drawText(2, 3, fmt.Sprintf(" %s %s %s %s", fit("#", 5), fit("NAME", 32), fit("CATEGORY", 16), "UPDATED"), styleHeader)
+
+	listH := h - 8
+	if len(b.list.Secrets) == 0 {
+		b.u.drawText(4, 5, "(no secrets on this page)", tcell.StyleDefault.Foreground(tcell.ColorDarkGray))
+	} else {
+		b.view.draw(s, 2, 4, w-4, listH)
+	}
+
+	pageInfo := fmt.Sprintf("Page %d of %d  ·  %d secrets", b.list.Page.Page, b.list.TotalPages, b.list.Total)
+	b.u.drawText(2, h-3, pageInfo, tcell.StyleDefault)
+	b.u.drawText(2, h-2, "↑↓=Move  PgUp/Dn=Page  Enter=Open  n=New  e=Edit  d=Delete  r=Reload  Esc=Back", styleHint)
+	s.Show()
+	b.u.flushStatus()
+}
+
+func (b *secretsBrowser) run() {
+	b.render()
+	for {
+		switch ev := b.u.s.PollEvent().(type) {
+		case *tcell.EventResize:
+			b.render()
+		case *tcell.EventKey:
+			_, h := b.u.s.Size()
+			switch ev.Key() {
+			case tcell.KeyEscape:
+				return
+			case tcell.KeyUp:
+				b.view.move(-1)
+			case tcell.KeyDown:
+				b.view.move(1)
+			case tcell.KeyHome:
+				b.view.move(-len(b.view.rows))
+			case tcell.KeyEnd:
+				b.view.move(len(b.view.rows))
+			case tcell.KeyPgDn:
+				if b.list.HasNext() {
+					b.load(b.list.Page.Page + 1)
+				} else {
+					b.view.move(h)
+				}
+			case tcell.KeyPgUp:
+				if b.list.Page.Page > 1 {
+					b.load(b.list.Page.Page - 1)
+				} else {
+					b.view.move(-h)
+				}
+			case tcell.KeyDelete:
+				b.delete()
+			case tcell.KeyEnter:
+				if sec := b.selected(); sec != nil {
+					b.u.ShowSecretDetail(sec)
+					b.view.setRows(b.rows())
+				}
+			case tcell.KeyRune:
+				switch ev.Rune() {
+				case 'q':
+					return
+				case 'j':
+					b.view.move(1)
+				case 'k':
+					b.view.move(-1)
+				case 'r':
+					b.load(b.list.Page.Page)
+				case 'n':
+					if b.u.ShowNewSecret() != nil {
+						b.reload()
+					}
+				case 'd':
+					b.delete()
+				case 'e':
+					if sec := b.selected(); sec != nil && b.u.ShowEditSecret(sec) != nil {
+						b.reload()
+					}
+				}
+			}
+			b.render()
+		case nil:
+			return
+		}
+	}
+}
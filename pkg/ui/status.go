@@ -7,15 +7,9 @@ import (
 )
 
 func DrawStatus(s tcell.Screen, msg string) {
+	drawStatusLine(s, msg)
 	w, h := s.Size()
 	style := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
-	for i := 0; i < w; i++ {
-		s.SetContent(i, h-1, ' ', nil, style)
-	}
-	for i, r := range msg {
-		s.SetContent(2+i, h-1, r, nil, style)
-	}
-	s.Show()
 	// auto clear after 5s
 	go func() {
 		time.Sleep(5 * time.Second)
@@ -25,3 +19,16 @@ func DrawStatus(s tcell.Screen, msg string) {
 		s.Show()
 	}()
 }
+
+// drawStatusLine draws msg on the status bar and leaves it there.
+func drawStatusLine(s tcell.Screen, msg string) {
+	w, h := s.Size()
+	style := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
+	for i := 0; i < w; i++ {
+		s.SetContent(i, h-1, ' ', nil, style)
+	}
+	for i, r := range []rune(msg) {
+		s.SetContent(2+i, h-1, r, nil, style)
+	}
+	s.Show()
+}
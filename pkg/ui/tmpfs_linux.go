@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	tmpfsMagic = 0x01021994
+	ramfsMagic = 0x858458f6
+)
+
+// memoryDir returns a writable, memory-backed directory: $XDG_RUNTIME_DIR
+// (per user, 0700) if it is tmpfs, otherwise /dev/shm.
+func memoryDir() (string, error) {
+	for _, dir := range []string{os.Getenv("XDG_RUNTIME_DIR"), "/dev/shm"} {
+		if dir == "" {
+			continue
+		}
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(dir, &st); err != nil {
+			continue
+		}
+		if t := uint32(st.Type); t == tmpfsMagic || t == ramfsMagic {
+			return dir, nil
+		}
+	}
+	return "", errNoMemoryDir
+}
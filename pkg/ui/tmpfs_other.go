@@ -0,0 +1,8 @@
+//go:build !linux
+
+package ui
+
+// memoryDir is only implemented on Linux, where tmpfs can be detected.
+func memoryDir() (string, error) {
+	return "", errNoMemoryDir
+}
@@ -1,10 +1,14 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"sm-cli/pkg/api"
+	"sm-cli/pkg/credstore"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -19,16 +23,52 @@ const logo = `
 
 const compactLogo = "Secrets Vault"
 
+// emailLookupTimeout bounds the footer's who-am-I request, and a failed
+// lookup is only repeated after emailRetryAfter, so menu redraws never wait
+// on a slow backend for long.
+const (
+	emailLookupTimeout = 2 * time.Second
+	emailRetryAfter    = 30 * time.Second
+)
+
+// sessionWarnBefore is how long before JWT expiry the footer starts warning.
+const sessionWarnBefore = 10 * time.Minute
+
 const tagline = "the best way to store your secrets instead of forgetting them."
 
 type UI struct {
-	s tcell.Screen
+	s       tcell.Screen
+	profile string
+	store   credstore.Store
+	status  string // shown once on the next screen render
+
+	defaultCategory string // pre-filled in the new secret form
+
+	// footer email, looked up once per token
+	email        string
+	emailToken   string
+	emailChecked time.Time
 }
 
 func New(s tcell.Screen) *UI {
 	return &UI{s: s}
 }
 
+// SetProfile sets the configuration profile name shown in the menu footer.
+func (u *UI) SetProfile(name string) {
+	u.profile = name
+}
+
+// SetDefaultCategory sets the category suggested for new secrets.
+func (u *UI) SetDefaultCategory(category string) {
+	u.defaultCategory = category
+}
+
+// SetCredentialStore makes successful logins persist across sessions.
+func (u *UI) SetCredentialStore(store credstore.Store) {
+	u.store = store
+}
+
 func (u *UI) DrawSplash(w, h int) {
 	// show static logo and menu
 	u.RenderMainMenu(0)
@@ -310,7 +350,7 @@ func (u *UI) RenderMainMenu(selected int) {
 
 	// if not logged in, show login hint below footer in bright red
 	if !api.HasToken() {
-		hint := "You are not logged in. Press 'L' to login with an API key."
+		hint := "You are not logged in. Press 'L' to login or 'S' to sign up."
 		red := tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
 		hx := startX + 2
 		hy := startY + totalHeight + 1
@@ -319,9 +359,11 @@ func (u *UI) RenderMainMenu(selected int) {
 		}
 	} else {
 		// show logged in user's email on footer right
-		email, err := api.GetCurrentUserEmail()
-		if err == nil && email != "" {
+		if email := u.currentEmail(); email != "" {
 			info := "Logged in: " + email
+			if u.profile != "" {
+				info += " [" + u.profile + "]"
+			}
 			// draw at right side of card
 			x := startX + blockWidth - 2 - utf8.RuneCountInString(info)
 			if x < startX+2 {
@@ -332,9 +374,63 @@ func (u *UI) RenderMainMenu(selected int) {
 				u.s.SetContent(x+i, statusY, r, nil, col)
 			}
 		}
+		// warn below the footer when the session is about to run out
+		if warn, st, ok := sessionWarning(); ok {
+			u.drawText(startX+2, statusY+1, warn, st)
+		}
 	}
 
 	u.s.Show()
+	u.flushStatus()
+}
+
+// currentEmail returns the logged-in user's email for the footer. It is
+// fetched when the token changes (login, restore, refresh) and cached.
+func (u *UI) currentEmail() string {
+	token := api.Default().Token()
+	if token == u.emailToken && (u.email != "" || time.Since(u.emailChecked) < emailRetryAfter) {
+		return u.email
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), emailLookupTimeout)
+	defer cancel()
+	email, err := api.Default().GetCurrentUserEmail(ctx)
+	if err != nil {
+		email = ""
+	}
+	u.email, u.emailToken, u.emailChecked = email, token, time.Now()
+	return email
+}
+
+// sessionWarning describes an expired or soon-expiring JWT. API keys and
+// tokens without an exp claim never warn.
+func sessionWarning() (string, tcell.Style, bool) {
+	claims, err := api.Default().TokenClaims()
+	if err != nil || claims.ExpiresAt.IsZero() {
+		return "", tcell.StyleDefault, false
+	}
+	left := time.Until(claims.ExpiresAt)
+	switch {
+	case left <= 0:
+		return "Session expired, it will be refreshed on the next request.", tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true), true
+	case left < sessionWarnBefore:
+		return fmt.Sprintf("Session expires in %s.", left.Round(time.Minute)), tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true), true
+	}
+	return "", tcell.StyleDefault, false
+}
+
+// setStatus queues msg for the status bar of the next screen render, so it
+// survives the caller redrawing after a sub-screen returns.
+func (u *UI) setStatus(msg string) {
+	u.status = msg
+}
+
+// flushStatus draws the queued status message, if any. Screens call it at the
+// end of their render.
+func (u *UI) flushStatus() {
+	if u.status != "" {
+		DrawStatus(u.s, u.status)
+		u.status = ""
+	}
 }
 
 func (u *UI) ShowMainMenu() {
@@ -350,7 +446,7 @@ func (u *UI) ShowHelp() {
 		"Navigation:",
 		"  - Use Up/Down arrow keys to move through the main menu.",
 		"  - Press Enter to select an item.",
-		"  - Shortcuts: L=Login, S=Signup, H=Help, Q=Quit",
+		"  - Shortcuts: L=Login, S=Signup, A=API Keys, H=Help, Q=Quit",
 		"",
 		"Features:",
 		"  - Login / Signup with email + master password",
@@ -423,7 +519,7 @@ func (u *UI) ShowHelp() {
 func (u *UI) MenuOptions() ([]string, []bool) {
 	// build menu depending on login state: if logged in, hide Login
 	if api.HasToken() {
-		menu := []string{"Secrets", "Help", "Quit"}
+		menu := []string{"Secrets", "API Keys", "Help", "Quit"}
 		sel := make([]bool, len(menu))
 		for i := range sel {
 			sel[i] = true
@@ -431,14 +527,14 @@ func (u *UI) MenuOptions() ([]string, []bool) {
 		return menu, sel
 	}
 
-	menu := []string{"Login", "Secrets", "Help", "Quit"}
+	menu := []string{"Login", "Signup", "Secrets", "API Keys", "Help", "Quit"}
 	sel := make([]bool, len(menu))
 	for i := range sel {
 		sel[i] = true
 	}
-	// Secrets disabled when not logged in
+	// Secrets and API Keys disabled when not logged in
 	for i, it := range menu {
-		if it == "Secrets" {
+		if it == "Secrets" || it == "API Keys" {
 			sel[i] = false
 		}
 	}
@@ -460,7 +556,7 @@ func (u *UI) ShowDisabledWarning(msg string) {
 	if downY < 0 {
 		downY = 0
 	}
-	text := "Hold on — " + msg + " requires login with an API key."
+	text := "Hold on — " + msg + " requires login."
 	for i := 0; i < w; i++ {
 		u.s.SetContent(i, downY, ' ', nil, tcell.StyleDefault)
 	}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"sm-cli/pkg/api"
+)
+
+func TestCurrentEmailIsCachedPerToken(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"id": "u1", "email": "a@b.c"}`))
+	}))
+	defer srv.Close()
+	old := api.Default()
+	defer api.SetDefault(old)
+	api.SetDefault(api.NewClient(api.WithBaseURL(srv.URL), api.WithToken("one")))
+
+	u := &UI{}
+	for i := 0; i < 3; i++ {
+		if got := u.currentEmail(); got != "a@b.c" {
+			t.Fatalf("currentEmail = %q", got)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("%d lookups for one token, want 1", calls)
+	}
+	api.SetToken("two")
+	u.currentEmail()
+	if calls != 2 {
+		t.Errorf("%d lookups after a token change, want 2", calls)
+	}
+}
+
+func TestCurrentEmailFailureIsNotRetriedOnEveryRedraw(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	old := api.Default()
+	defer api.SetDefault(old)
+	api.SetDefault(api.NewClient(api.WithBaseURL(srv.URL), api.WithToken("one")))
+
+	u := &UI{}
+	u.currentEmail()
+	u.currentEmail()
+	if calls != 1 {
+		t.Errorf("%d lookups, want 1", calls)
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// validateEmail accepts a bare address with a dotted domain, e.g. a@b.io.
+func validateEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return fmt.Errorf("%q is not a valid email address", email)
+	}
+	at := strings.LastIndex(email, "@")
+	if !strings.Contains(email[at+1:], ".") {
+		return fmt.Errorf("%q is missing a domain", email)
+	}
+	return nil
+}
+
+// validatePassword applies the minimum rules for the account password.
+func validatePassword(pw string) error {
+	if len([]rune(pw)) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+	return nil
+}
+
+// validateMasterPassword enforces a stronger policy for the master password,
+// which encrypts every secret and cannot be recovered.
+func validateMasterPassword(master, password string) error {
+	if len([]rune(master)) < 12 {
+		return fmt.Errorf("master password must be at least 12 characters")
+	}
+	var lower, upper, digit, symbol bool
+	for _, r := range master {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+	if classes < 3 {
+		return fmt.Errorf("master password needs 3 of: lowercase, uppercase, digits, symbols")
+	}
+	if master == password {
+		return fmt.Errorf("master password must differ from the account password")
+	}
+	return nil
+}
+
+// validateSecret checks the fields of the new/edit secret form.
+func validateSecret(name, value, category, description string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("name is required")
+	case name != strings.TrimSpace(name):
+		return fmt.Errorf("name must not start or end with spaces")
+	case len([]rune(name)) > 128:
+		return fmt.Errorf("name must be at most 128 characters")
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return fmt.Errorf("name must not contain control characters")
+	case value == "":
+		return fmt.Errorf("value is required")
+	case len([]rune(category)) > 64:
+		return fmt.Errorf("category must be at most 64 characters")
+	case len([]rune(description)) > 512:
+		return fmt.Errorf("description must be at most 512 characters")
+	}
+	return nil
+}